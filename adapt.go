@@ -0,0 +1,135 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import "math"
+
+// deMemory holds the success-history memories of F and CR of one group (SHADE)
+type deMemory struct {
+	F  []float64 // [DEmem] memory of F
+	CR []float64 // [DEmem] memory of CR
+	K  int       // index of the next item to be updated
+
+	// successful values of the current generation
+	sF  []float64 // values of F
+	sCR []float64 // values of CR
+	sW  []float64 // weights; i.e. improvements of ova[0]
+}
+
+// newDEmemory allocates a new success-history memory with all items equal to 0.5
+func newDEmemory(size int) (o *deMemory) {
+	o = new(deMemory)
+	o.F = make([]float64, size)
+	o.CR = make([]float64, size)
+	o.reset()
+	return
+}
+
+// reset sets all items equal to 0.5 and clears the successful values
+func (o *deMemory) reset() {
+	for i := range o.F {
+		o.F[i], o.CR[i] = 0.5, 0.5
+	}
+	o.K = 0
+	o.sF, o.sCR, o.sW = o.sF[:0], o.sCR[:0], o.sW[:0]
+}
+
+// success records the values of F and CR of an offspring that has replaced its parent. Offspring
+// without values (e.g. created by CxFlt) are ignored
+func (o *deMemory) success(F, CR, improvement float64) {
+	if F <= 0 {
+		return
+	}
+	o.sF = append(o.sF, F)
+	o.sCR = append(o.sCR, CR)
+	o.sW = append(o.sW, math.Abs(improvement))
+}
+
+// update updates the K-th item of the memories with the weighted Lehmer mean of the successful F
+// and the weighted mean of the successful CR. Equal weights are used if there is no improvement
+func (o *deMemory) update() {
+	if len(o.sF) == 0 {
+		return
+	}
+	var sumW float64
+	for _, w := range o.sW {
+		sumW += w
+	}
+	var sumF, sumF2, sumCR float64
+	for i := range o.sF {
+		w := 1.0 / float64(len(o.sF))
+		if sumW > 0 {
+			w = o.sW[i] / sumW
+		}
+		sumF += w * o.sF[i]
+		sumF2 += w * o.sF[i] * o.sF[i]
+		sumCR += w * o.sCR[i]
+	}
+	if sumF > 0 {
+		o.F[o.K] = sumF2 / sumF
+	}
+	o.CR[o.K] = sumCR
+	o.K = (o.K + 1) % len(o.F)
+	o.sF, o.sCR, o.sW = o.sF[:0], o.sCR[:0], o.sW[:0]
+}
+
+// deControl returns the scale factor F and the crossover rate CR to create the offspring a of the
+// parent A by differential evolution. With self-adaptation, the values are stored in a:
+//   jde   -- the values of A are inherited; but regenerated with probability DEtau; F in [0.1,1)
+//            and CR in [0,1). A parent without values uses DEF (0.5 if zero) and DEC
+//   shade -- the values are drawn from a random item of the memories of the group; F from a
+//            Cauchy distribution with scale 0.1 and CR from a normal distribution with σ = 0.1
+//  Note: F ≤ 0 means that diffEvol draws a random F
+func (o *Optimiser) deControl(cpu int, a, A *Solution) (F, CR float64) {
+	grp := o.Groups[cpu]
+	prms, rng := grp.Prms, grp.Rnd
+	switch prms.DEadapt {
+	case "jde":
+		F, CR = A.DEF, A.DEC
+		if F == 0 {
+			F, CR = prms.DEF, prms.DEC
+			if F == 0 {
+				F = 0.5
+			}
+		}
+		if rng.FlipCoin(prms.DEtau) {
+			F = rng.Float64(0.1, 1.0)
+		}
+		if rng.FlipCoin(prms.DEtau) {
+			CR = rng.Float64(0.0, 1.0)
+		}
+	case "shade":
+		r := rng.Int(0, len(grp.deMem.F)-1)
+		CR = math.Min(math.Max(rng.Normal(grp.deMem.CR[r], 0.1), 0), 1)
+		for F <= 0 {
+			F = rng.Cauchy(grp.deMem.F[r], 0.1)
+		}
+		F = math.Min(F, 1)
+	default:
+		return prms.DEF, prms.DEC
+	}
+	a.DEF, a.DEC = F, CR
+	return
+}
+
+// meanDEcontrol returns the mean values of F and CR of the current solutions. INF is returned if
+// there is no self-adaptation or no solution has values yet
+func (o *Optimiser) meanDEcontrol() (F, CR float64) {
+	if o.DEadapt == "" {
+		return INF, INF
+	}
+	n := 0
+	for _, sol := range o.Solutions {
+		if sol.DEF > 0 {
+			F += sol.DEF
+			CR += sol.DEC
+			n++
+		}
+	}
+	if n == 0 {
+		return INF, INF
+	}
+	return F / float64(n), CR / float64(n)
+}
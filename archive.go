@@ -0,0 +1,153 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/cpmech/gosl/utl"
+)
+
+// Archive holds copies of the feasible non-dominated solutions found during the evolution. The
+// archive is updated after every evaluation; thus, good solutions are kept even if they are later
+// replaced in Solutions. When the archive is full, the solution with the smallest crowd distance
+// is discarded; or the one with the smallest hypervolume contribution if Prune == "hv" and Nova == 2
+//  Note: all methods can be called concurrently
+type Archive struct {
+	Size  int    // maximum number of solutions
+	Prune string // pruning rule when the archive is full: "crowd" or "hv"
+	mutex sync.Mutex
+	prms  *Parameters // parameters
+	sols  []*Solution // non-dominated solutions
+}
+
+// NewArchive allocates a new archive
+//  Input:
+//   size  -- maximum number of solutions
+//   prune -- pruning rule when the archive is full: "crowd" or "hv"
+//   prms  -- parameters
+func NewArchive(size int, prune string, prms *Parameters) (o *Archive) {
+	o = new(Archive)
+	o.Size = size
+	o.Prune = prune
+	o.prms = prms
+	return
+}
+
+// Add adds a copy of sol to the archive if sol is feasible and not dominated by (or equal to) any
+// solution in the archive. The solutions dominated by sol are removed
+func (o *Archive) Add(sol *Solution) (added bool) {
+	if !sol.Feasible() {
+		return
+	}
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	n := 0
+	for _, a := range o.sols {
+		aDom, sDom := utl.ParetoMin(a.Ova, sol.Ova)
+		if aDom || equalOvas(a.Ova, sol.Ova) {
+			return
+		}
+		if !sDom {
+			o.sols[n] = a
+			n++
+		}
+	}
+	o.sols = o.sols[:n]
+	cpy := NewSolution(sol.Id, 0, o.prms)
+	sol.CopyInto(cpy)
+	o.sols = append(o.sols, cpy)
+	if len(o.sols) > o.Size {
+		o.prune()
+	}
+	return true
+}
+
+// Solutions returns copies of the solutions in the archive sorted in ascending order of ova[0]
+func (o *Archive) Solutions() (res []*Solution) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	res = make([]*Solution, len(o.sols))
+	for i, sol := range o.sols {
+		res[i] = NewSolution(sol.Id, 0, o.prms)
+		sol.CopyInto(res[i])
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Ova[0] < res[j].Ova[0] })
+	return
+}
+
+// Len returns the number of solutions in the archive
+func (o *Archive) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return len(o.sols)
+}
+
+// Clear removes all solutions from the archive
+func (o *Archive) Clear() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.sols = nil
+}
+
+// prune removes the solution that contributes the least to the archive
+func (o *Archive) prune() {
+	var contrib []float64
+	if o.Prune == "hv" && o.prms.Nova == 2 {
+		contrib = o.hvContributions()
+	} else {
+		contrib = o.crowdDistances()
+	}
+	imin := 0
+	for i, c := range contrib {
+		if c < contrib[imin] {
+			imin = i
+		}
+	}
+	o.sols = append(o.sols[:imin], o.sols[imin+1:]...)
+}
+
+// crowdDistances computes the crowd distances of the solutions in the archive. The distances of
+// the extreme solutions are infinite
+func (o *Archive) crowdDistances() (dist []float64) {
+	n := len(o.sols)
+	dist = make([]float64, n)
+	idx := utl.IntRange(n)
+	for k := 0; k < o.prms.Nova; k++ {
+		sort.Slice(idx, func(i, j int) bool { return o.sols[idx[i]].Ova[k] < o.sols[idx[j]].Ova[k] })
+		fmin, fmax := o.sols[idx[0]].Ova[k], o.sols[idx[n-1]].Ova[k]
+		dist[idx[0]], dist[idx[n-1]] = INF, INF
+		for i := 1; i < n-1; i++ {
+			dist[idx[i]] += (o.sols[idx[i+1]].Ova[k] - o.sols[idx[i-1]].Ova[k]) / (fmax - fmin + 1e-15)
+		}
+	}
+	return
+}
+
+// hvContributions computes the hypervolume contributions of the solutions in a two-objective
+// archive. The contributions of the extreme solutions are infinite
+func (o *Archive) hvContributions() (hv []float64) {
+	n := len(o.sols)
+	hv = make([]float64, n)
+	idx := utl.IntRange(n)
+	sort.Slice(idx, func(i, j int) bool { return o.sols[idx[i]].Ova[0] < o.sols[idx[j]].Ova[0] })
+	hv[idx[0]], hv[idx[n-1]] = INF, INF
+	for i := 1; i < n-1; i++ {
+		a, b, c := o.sols[idx[i-1]], o.sols[idx[i]], o.sols[idx[i+1]]
+		hv[idx[i]] = (c.Ova[0] - b.Ova[0]) * (a.Ova[1] - b.Ova[1])
+	}
+	return
+}
+
+// equalOvas tells whether a and b are equal or not
+func equalOvas(a, b []float64) bool {
+	for i := 0; i < len(a); i++ {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import "github.com/cpmech/gosl/chk"
+
+// InitAskTell initialises the optimiser to be driven from outside by Ask and Tell; i.e. the
+// objective values are computed externally and there is no need of ObjFunc or MinProb
+//  Input:
+//   gen  -- generator of solutions; may be nil (see Init)
+//   nova -- number of objective values
+//   noor -- number of out-of-range values
+func (o *Optimiser) InitAskTell(gen Generator_t, nova, noor int) {
+	o.ObjFunc, o.ObjFuncErr, o.BatchObjFunc = nil, nil, nil
+	o.MinProb, o.MinProbErr = nil, nil
+	o.Nova, o.Noor = nova, noor
+	err := o.initialise(gen)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}
+
+// Ask returns the next batch of solutions to be evaluated. The first call after InitAskTell (or
+// Reset) returns the initial solutions; the next calls return the offspring of all groups. Ask
+// returns nil after Tell has reported that the run is done; Reset must be called to start again.
+//  Note: the Ova and Oor values of the returned solutions must be filled and the solutions given
+//        back to Tell before calling Ask again. The returned slice must not be modified
+func (o *Optimiser) Ask() (sols []*Solution) {
+	if o.asked != nil {
+		chk.Panic("Tell must be called before calling Ask again")
+	}
+	if o.askDone {
+		return nil
+	}
+	if o.askIni {
+		o.asked = append([]*Solution{}, o.Solutions...)
+		return o.asked
+	}
+	if o.tcur == 0 {
+		o.History = nil
+		o.initTerminators()
+		o.initObservers()
+		if o.Output != nil {
+			o.Output(0, o.Solutions)
+		}
+	}
+	o.asked = make([]*Solution, 0, o.Nsol)
+	for cpu := 0; cpu < o.Ncpu; cpu++ {
+		o.asked = append(o.asked, o.genOffspring(cpu)...)
+	}
+	return o.asked
+}
+
+// Tell receives the solutions returned by Ask with their Ova and Oor values filled. Then, the
+// tournaments among current solutions and offspring are performed and, at exchange times, the
+// solutions are exchanged between groups as in Solve
+//  Input:
+//   sols -- evaluated solutions; either the ones returned by Ask or copies in the same order
+//  Output:
+//   done -- Tmax has been reached or a Terminator is done. The reason is recorded in StopReason
+//  Note: Tell panics if an evaluation fails and FailPolicy == "abort"; see TellErr
+func (o *Optimiser) Tell(sols []*Solution) (done bool) {
+	done, err := o.TellErr(sols)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	return
+}
+
+// TellErr is the same as Tell but returns an error instead of panicking
+//  Output:
+//   done -- Tmax has been reached, a Terminator is done or an evaluation has failed
+//   err  -- the error of a failed evaluation if FailPolicy == "abort"; or the error of a misuse
+//           of Ask and Tell
+func (o *Optimiser) TellErr(sols []*Solution) (done bool, err error) {
+
+	// check
+	if o.asked == nil {
+		return false, chk.Err("Ask must be called before Tell")
+	}
+	if len(sols) != len(o.asked) {
+		return false, chk.Err("number of solutions given to Tell (%d) must be equal to the number returned by Ask (%d)", len(sols), len(o.asked))
+	}
+
+	// collect objective values. solutions with NaN values cannot be re-sampled; thus they are
+	// marked as infeasible unless FailPolicy == "abort"
+	for i, sol := range sols {
+		if sol != o.asked[i] {
+			copy(o.asked[i].Ova, sol.Ova)
+			copy(o.asked[i].Oor, sol.Oor)
+		}
+		if e := checkNaN(o.asked[i]); e != nil {
+			if o.FailPolicy == "abort" {
+				err = chk.Err("evaluation of solution %d failed:\n%v", o.asked[i].Id, e)
+				o.asked = nil
+				o.askDone = true
+				return true, err
+			}
+			setInfeasible(o.asked[i])
+			o.Nfailed++
+			continue
+		}
+		if o.Archive != nil {
+			o.Archive.Add(o.asked[i])
+		}
+	}
+	o.Nfeval += len(sols)
+	o.asked = nil
+
+	// initial solutions
+	if o.askIni {
+		o.askIni = false
+		o.Metrics.Compute(o.Solutions)
+		return false, nil
+	}
+
+	// tournaments
+	o.tcur++
+	for cpu := 0; cpu < o.Ncpu; cpu++ {
+		o.selectSurvivors(cpu)
+		o.Groups[cpu].Nfeval += len(o.Groups[cpu].Offspring)
+		o.generationDone(cpu, o.tcur)
+	}
+
+	// exchange
+	if o.tcur%o.DtExc == 0 || o.tcur >= o.Tmax {
+		o.Metrics.Compute(o.Solutions)
+		o.recordOva0()
+		o.recordHistory(o.tcur)
+		migrated := o.exchange()
+		o.buildMeshes()
+		if o.Output != nil {
+			o.Output(o.tcur, o.Solutions)
+		}
+		o.exchangeDone(o.tcur, migrated)
+		if o.terminated() {
+			o.askDone = true
+			o.runFinished()
+			return true, nil
+		}
+	}
+
+	// final time
+	if o.tcur >= o.Tmax {
+		o.StopReason = "tmax"
+		o.askDone = true
+		o.runFinished()
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"container/list"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// Cache holds the objective values of previously evaluated solutions. The key of each entry is
+// made of the floats (rounded to multiples of Tol) and the ints of a solution. When the cache is
+// full, the least recently used entry is discarded.
+//  Note: all methods can be called concurrently
+type Cache struct {
+	Size  int     // maximum number of entries
+	Tol   float64 // tolerance to compare floats; zero means exact comparison
+	mutex sync.Mutex
+	lru   *list.List               // entries; most recently used first
+	items map[string]*list.Element // maps keys to entries in lru
+	nhits int                      // number of hits since last ResetHits
+}
+
+// cacheEntry holds the objective values of a solution
+type cacheEntry struct {
+	key string
+	ova []float64
+	oor []float64
+}
+
+// NewCache allocates a new cache
+//  Input:
+//   size -- maximum number of entries
+//   tol  -- tolerance to compare floats; zero means exact comparison
+func NewCache(size int, tol float64) (o *Cache) {
+	o = new(Cache)
+	o.Size = size
+	o.Tol = tol
+	o.lru = list.New()
+	o.items = make(map[string]*list.Element)
+	return
+}
+
+// Get sets the Ova and Oor values of sol if an equivalent solution is in the cache
+func (o *Cache) Get(sol *Solution) (found bool) {
+	key := o.key(sol)
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	elem, found := o.items[key]
+	if !found {
+		return
+	}
+	o.lru.MoveToFront(elem)
+	entry := elem.Value.(*cacheEntry)
+	copy(sol.Ova, entry.ova)
+	copy(sol.Oor, entry.oor)
+	o.nhits++
+	return
+}
+
+// addHit counts a hit that has not been found by Get; e.g. a duplicate in a batch of solutions
+func (o *Cache) addHit() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.nhits++
+}
+
+// Put adds the objective values of sol to the cache
+func (o *Cache) Put(sol *Solution) {
+	key := o.key(sol)
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	if elem, found := o.items[key]; found {
+		o.lru.MoveToFront(elem)
+		entry := elem.Value.(*cacheEntry)
+		copy(entry.ova, sol.Ova)
+		copy(entry.oor, sol.Oor)
+		return
+	}
+	entry := &cacheEntry{key, make([]float64, len(sol.Ova)), make([]float64, len(sol.Oor))}
+	copy(entry.ova, sol.Ova)
+	copy(entry.oor, sol.Oor)
+	o.items[key] = o.lru.PushFront(entry)
+	for o.lru.Len() > o.Size {
+		last := o.lru.Back()
+		delete(o.items, last.Value.(*cacheEntry).key)
+		o.lru.Remove(last)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (o *Cache) Len() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.lru.Len()
+}
+
+// Hits returns the number of hits since the last call to ResetHits
+func (o *Cache) Hits() int {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return o.nhits
+}
+
+// ResetHits sets the number of hits to zero
+func (o *Cache) ResetHits() {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.nhits = 0
+}
+
+// key returns the key corresponding to sol. Each float is preceded by a tag telling whether it has
+// been rounded to a multiple of Tol (1) or not (0); the latter happens if Tol is zero or the number
+// of multiples does not fit in an int64 (e.g. x/Tol > 9.2e18 or x = ±Inf)
+func (o *Cache) key(sol *Solution) string {
+	b := make([]byte, 9*len(sol.Flt)+8*len(sol.Int))
+	k := 0
+	for _, x := range sol.Flt {
+		q := math.Floor(x/o.Tol + 0.5)
+		if o.Tol > 0 && math.Abs(q) < 1<<62 {
+			b[k] = 1
+			binary.LittleEndian.PutUint64(b[k+1:], uint64(int64(q)))
+		} else {
+			b[k] = 0
+			binary.LittleEndian.PutUint64(b[k+1:], math.Float64bits(x))
+		}
+		k += 9
+	}
+	for _, y := range sol.Int {
+		binary.LittleEndian.PutUint64(b[k:], uint64(int64(y)))
+		k += 8
+	}
+	return string(b)
+}
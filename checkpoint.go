@@ -0,0 +1,249 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// solutionData holds the essential data of a Solution for serialisation
+type solutionData struct {
+	Id    int       // identifier
+	Fixed bool      // cannot be changed
+	Ova   []float64 // objective values
+	Oor   []float64 // out-of-range values
+	Flt   []float64 // floats
+	Int   []int     // ints
+	Aux   float64   // auxiliary data
+	DEF   float64   // scale factor F of self-adaptive differential evolution
+	DEC   float64   // crossover rate CR of self-adaptive differential evolution
+}
+
+// newSolutionData returns the essential data of sol. The slices are shared
+func newSolutionData(sol *Solution) *solutionData {
+	return &solutionData{sol.Id, sol.Fixed, sol.Ova, sol.Oor, sol.Flt, sol.Int, sol.Aux, sol.DEF, sol.DEC}
+}
+
+// copyInto copies the data into sol, including the Fixed flag
+func (o *solutionData) copyInto(sol *Solution) {
+	sol.Id = o.Id
+	sol.Fixed = o.Fixed
+	copy(sol.Ova, o.Ova)
+	copy(sol.Oor, o.Oor)
+	copy(sol.Flt, o.Flt)
+	copy(sol.Int, o.Int)
+	sol.Aux = o.Aux
+	sol.DEF = o.DEF
+	sol.DEC = o.DEC
+}
+
+// checkpoint holds the state of a running optimisation
+type checkpoint struct {
+	Nsol      int             // total number of solutions; may be greater than Nsol0 due to restarts
+	Nsol0     int             // initial number of solutions; i.e. before restarts with growth
+	Nova      int             // number of objective values
+	Noor      int             // number of out-of-range values
+	Nflt      int             // number of floats
+	Nint      int             // number of ints
+	Time      int             // current time
+	Nfeval    int             // number of function evaluations
+	Iova0     int             // index of current item in Ova0
+	Ova0      []float64       // history of best feasible ova[0]
+	Groups    [][]int         // [ncpu][ncur] indices of solutions in each group
+	RndStates []uint64        // [1+ncpu] states of random numbers streams: optimiser then groups
+	Solutions []*solutionData // current solutions
+
+	// restarts and archive
+	Restarts []*RestartData  // restarts performed so far
+	Best     *solutionData   // best feasible solution found so far, including previous restarts; may be nil
+	Archive  []*solutionData // solutions in Archive (only if ArchSize > 0)
+
+	// self-adaptive differential evolution
+	Memories []*deMemory // [ncpu] success-history memories of F and CR (only if DEadapt == "shade")
+}
+
+// SaveCheckpoint saves the current state of the optimisation to file. This function must be called
+// at exchange times; e.g. from the Output function. After resuming with LoadCheckpoint, Solve
+// continues with the same trajectory as the run that saved the checkpoint.
+func (o *Optimiser) SaveCheckpoint(path string) (err error) {
+
+	// save file. use temporary file to avoid corrupting a previous checkpoint
+	b, err := json.Marshal(o.newCheckpoint())
+	if err != nil {
+		return chk.Err("cannot marshal checkpoint:\n%v", err)
+	}
+	tmp := path + ".tmp"
+	err = ioutil.WriteFile(tmp, b, 0644)
+	if err != nil {
+		return chk.Err("cannot write checkpoint file %q:\n%v", tmp, err)
+	}
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return chk.Err("cannot rename checkpoint file %q:\n%v", tmp, err)
+	}
+	return
+}
+
+// newCheckpoint returns the current state of the optimisation. The slices are shared
+func (o *Optimiser) newCheckpoint() (ck *checkpoint) {
+	ck = &checkpoint{
+		Nsol:      o.Nsol,
+		Nsol0:     o.nsol0,
+		Nova:      o.Nova,
+		Noor:      o.Noor,
+		Nflt:      o.Nflt,
+		Nint:      o.Nint,
+		Time:      o.tcur,
+		Nfeval:    o.Nfeval,
+		Iova0:     o.iova0,
+		Ova0:      o.ova0,
+		Groups:    make([][]int, len(o.Groups)),
+		RndStates: make([]uint64, 1+len(o.Groups)),
+		Solutions: make([]*solutionData, len(o.Solutions)),
+		Restarts:  o.Restarts,
+	}
+	index := make(map[*Solution]int)
+	for i, sol := range o.Solutions {
+		index[sol] = i
+		ck.Solutions[i] = newSolutionData(sol)
+	}
+	if o.Best != nil {
+		ck.Best = newSolutionData(o.Best)
+	}
+	if o.Archive != nil {
+		for _, sol := range o.Archive.sols {
+			ck.Archive = append(ck.Archive, newSolutionData(sol))
+		}
+	}
+	ck.RndStates[0] = o.Rnd.State
+	for cpu, grp := range o.Groups {
+		ck.RndStates[1+cpu] = grp.Rnd.State
+		ck.Groups[cpu] = make([]int, grp.Ncur)
+		for i := 0; i < grp.Ncur; i++ {
+			ck.Groups[cpu][i] = index[grp.All[i]]
+		}
+		if grp.deMem != nil {
+			ck.Memories = append(ck.Memories, grp.deMem)
+		}
+	}
+	return
+}
+
+// LoadCheckpoint loads the state of an optimisation saved by SaveCheckpoint. The Optimiser must
+// have been initialised (with Init) using the same parameters. The next call to Solve resumes the
+// evolution from the time recorded in the checkpoint.
+//  Note: Solutions and Groups are re-allocated if the population has grown due to restarts
+func (o *Optimiser) LoadCheckpoint(path string) (err error) {
+
+	// read file
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return chk.Err("cannot read checkpoint file %q:\n%v", path, err)
+	}
+	var ck checkpoint
+	err = json.Unmarshal(b, &ck)
+	if err != nil {
+		return chk.Err("cannot unmarshal checkpoint file %q:\n%v", path, err)
+	}
+	return o.loadCheckpoint(&ck, path)
+}
+
+// loadCheckpoint sets the state of the optimisation. name is used in error messages
+func (o *Optimiser) loadCheckpoint(ck *checkpoint, name string) (err error) {
+
+	// check
+	if ck.Nsol0 == 0 {
+		ck.Nsol0 = ck.Nsol // file written before Nsol0 was saved
+	}
+	if ck.Nsol0 != o.nsol0 || ck.Nova != o.Nova || ck.Noor != o.Noor || ck.Nflt != o.Nflt || ck.Nint != o.Nint {
+		return chk.Err("checkpoint %q is not compatible with this optimiser: (Nsol0,Nova,Noor,Nflt,Nint) = (%d,%d,%d,%d,%d) != (%d,%d,%d,%d,%d)",
+			name, ck.Nsol0, ck.Nova, ck.Noor, ck.Nflt, ck.Nint, o.nsol0, o.Nova, o.Noor, o.Nflt, o.Nint)
+	}
+	if ck.Nsol < ck.Nsol0 || len(ck.Solutions) != ck.Nsol || len(ck.Groups) != len(o.Groups) || len(ck.RndStates) != 1+len(o.Groups) {
+		return chk.Err("checkpoint %q has %d solutions, %d groups and %d random streams; but %d (at least %d), %d and %d are required",
+			name, len(ck.Solutions), len(ck.Groups), len(ck.RndStates), ck.Nsol, ck.Nsol0, len(o.Groups), 1+len(o.Groups))
+	}
+	for cpu := range o.Groups {
+		start, endp1 := o.groupRangeN(cpu, ck.Nsol)
+		if len(ck.Groups[cpu]) != endp1-start {
+			return chk.Err("group %d in checkpoint %q has %d solutions; but %d are required", cpu, name, len(ck.Groups[cpu]), endp1-start)
+		}
+		for _, idx := range ck.Groups[cpu] {
+			if idx < 0 || idx >= ck.Nsol {
+				return chk.Err("index of solution %d in group %d of checkpoint %q is invalid", idx, cpu, name)
+			}
+		}
+	}
+	for cpu, grp := range o.Groups {
+		if grp.deMem != nil && (len(ck.Memories) != len(o.Groups) || len(ck.Memories[cpu].F) != len(grp.deMem.F) || len(ck.Memories[cpu].CR) != len(grp.deMem.CR)) {
+			return chk.Err("checkpoint %q does not have the success-history memories of F and CR required by DEadapt = %q", name, o.DEadapt)
+		}
+	}
+	if len(ck.Archive) > 0 && o.Archive == nil {
+		return chk.Err("checkpoint %q has an archive; but ArchSize = %d", name, o.ArchSize)
+	}
+	sols := append([]*solutionData{}, ck.Solutions...)
+	sols = append(sols, ck.Archive...)
+	if ck.Best != nil {
+		sols = append(sols, ck.Best)
+	}
+	for i, s := range sols {
+		if len(s.Ova) != o.Nova || len(s.Oor) != o.Noor || len(s.Flt) != o.Nflt || len(s.Int) != o.Nint {
+			return chk.Err("solution %d in checkpoint %q has invalid number of values", i, name)
+		}
+	}
+
+	// solutions
+	if ck.Nsol != o.Nsol {
+		o.resize(ck.Nsol)
+	}
+	for i, s := range ck.Solutions {
+		s.copyInto(o.Solutions[i])
+	}
+
+	// groups
+	o.Rnd.State = ck.RndStates[0]
+	for cpu, grp := range o.Groups {
+		grp.Rnd.State = ck.RndStates[1+cpu]
+		if grp.deMem != nil {
+			copy(grp.deMem.F, ck.Memories[cpu].F)
+			copy(grp.deMem.CR, ck.Memories[cpu].CR)
+			grp.deMem.K = ck.Memories[cpu].K
+		}
+		for i, idx := range ck.Groups[cpu] {
+			grp.All[i] = o.Solutions[idx]
+		}
+	}
+
+	// restarts and archive
+	o.Restarts = ck.Restarts
+	o.Best = nil
+	if ck.Best != nil {
+		o.Best = NewSolution(ck.Best.Id, 0, &o.Parameters)
+		ck.Best.copyInto(o.Best)
+	}
+	if o.Archive != nil {
+		o.Archive.Clear()
+		for _, s := range ck.Archive {
+			sol := NewSolution(s.Id, 0, &o.Parameters)
+			s.copyInto(sol)
+			o.Archive.sols = append(o.Archive.sols, sol)
+		}
+	}
+
+	// state
+	o.tcur = ck.Time
+	o.resume = true
+	o.Nfeval = ck.Nfeval
+	o.iova0 = ck.Iova0
+	o.ova0 = ck.Ova0
+	o.Metrics.Compute(o.Solutions)
+	o.buildMeshes()
+	return
+}
@@ -6,24 +6,56 @@ package goga
 
 // constants
 const (
-	INF = 1e+30 // infinite distance
+	INF       = 1e+30 // infinite distance
+	NRESAMPLE = 10    // maximum number of times the float operators are applied if FltBound == "resample"
 )
 
 // Generator_t defines callback function to generate trial solutions
 type Generator_t func(sols []*Solution, prms *Parameters, reset bool)
 
+// GeneratorRng_t defines callback function to generate trial solutions with the given stream of
+// random numbers
+type GeneratorRng_t func(sols []*Solution, prms *Parameters, reset bool, rng *Rnd)
+
 // ObjFunc_t defines the objective fuction
+//  Note: cpu is the index of the group; or the index of the worker if Nworkers > 0. With
+//        NsamplesPll > 1, RunMany shifts cpu by max(Ncpu,Nworkers) for each trial running at the
+//        same time; thus, cpu < NsamplesPll * max(Ncpu,Nworkers)
 type ObjFunc_t func(sol *Solution, cpu int)
 
+// ObjFuncErr_t defines the objective function that may fail
+type ObjFuncErr_t func(sol *Solution, cpu int) error
+
+// BatchObjFunc_t defines the objective function that evaluates many solutions at once
+type BatchObjFunc_t func(sols []*Solution, cpu int)
+
 // MinProb_t defines objective functon for specialised minimisation problem
 type MinProb_t func(f, g, h, x []float64, y []int, cpu int)
 
+// MinProbErr_t defines objective function for specialised minimisation problem that may fail
+type MinProbErr_t func(f, g, h, x []float64, y []int, cpu int) error
+
 // CxInt_t defines crossover function for ints
 type CxInt_t func(a, b, A, B []int, prms *Parameters)
 
 // MtInt_t defines mutation function for ints
 type MtInt_t func(a []int, prms *Parameters)
 
+// CxIntRng_t defines crossover function for ints with the given stream of random numbers
+type CxIntRng_t func(a, b, A, B []int, prms *Parameters, rng *Rnd)
+
+// MtIntRng_t defines mutation function for ints with the given stream of random numbers
+type MtIntRng_t func(a []int, prms *Parameters, rng *Rnd)
+
+// CxFlt_t defines crossover function for floats; a and b are the offspring of parents A and B.
+// The values are not normalised. Values out of FltMin and FltMax are brought back into range
+// according to FltBound (see Parameters.BoundFlt); with A and B as parents of a and b
+type CxFlt_t func(a, b, A, B []float64, prms *Parameters, rng *Rnd)
+
+// MtFlt_t defines mutation function for floats. As in CxFlt_t, values out of range are handled
+// according to FltBound
+type MtFlt_t func(a []float64, prms *Parameters, rng *Rnd)
+
 // Output_t defines a function to perform output of data during the evolution
 type Output_t func(time int, sols []*Solution)
 
@@ -0,0 +1,225 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"sync"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// evaluate computes the objective values of solutions; all at once if BatchObjFunc is set. The
+// solutions found in Cache are not evaluated; neither are the duplicates (according to Cache) of
+// other solutions in sols, which receive the values of the first one. Failed evaluations are
+// handled according to FailPolicy. The evaluated solutions are added to Archive. If Nworkers > 0,
+// the solutions are evaluated by the workers; otherwise they are evaluated in this goroutine with
+// the cpu index
+//  Output:
+//   nfeval -- number of function evaluations, including re-samplings
+func (o *Optimiser) evaluate(sols []*Solution, cpu int) (nfeval int) {
+
+	// skip solutions in cache and duplicates
+	var dups [][2]*Solution // [duplicate, original]
+	if o.Cache != nil {
+		var missing []*Solution
+		first := make(map[string]*Solution)
+		for _, sol := range sols {
+			if o.Cache.Get(sol) {
+				continue
+			}
+			key := o.Cache.key(sol)
+			if orig, found := first[key]; found {
+				dups = append(dups, [2]*Solution{sol, orig})
+				continue
+			}
+			first[key] = sol
+			missing = append(missing, sol)
+		}
+		sols = missing
+	}
+	if len(sols) == 0 || o.Groups[cpu].err != nil {
+		return
+	}
+
+	// evaluate
+	var wg sync.WaitGroup
+	failed := make([]error, len(sols))
+	if o.BatchObjFunc != nil {
+		o.submit(cpu, &wg, func(icpu int) {
+			o.BatchObjFunc(sols, o.cpuOffset+icpu)
+		})
+		wg.Wait()
+		for i, sol := range sols {
+			failed[i] = checkNaN(sol)
+		}
+	} else {
+		for i, sol := range sols {
+			i, sol := i, sol
+			o.submit(cpu, &wg, func(icpu int) {
+				failed[i] = o.evaluateOne(sol, icpu)
+			})
+		}
+		wg.Wait()
+	}
+	nfeval = len(sols)
+
+	// handle failures and update cache and archive
+	failedSol := make(map[*Solution]bool)
+	for i, sol := range sols {
+		if failed[i] != nil {
+			failedSol[sol] = true
+			n, ok := o.recover(sol, cpu, failed[i])
+			nfeval += n
+			if !ok {
+				continue
+			}
+		}
+		if o.Cache != nil {
+			o.Cache.Put(sol)
+		}
+		if o.Archive != nil {
+			o.Archive.Add(sol)
+		}
+	}
+
+	// duplicates. those of failed solutions are evaluated because the original may have been
+	// re-sampled
+	var retry []*Solution
+	for _, pair := range dups {
+		if failedSol[pair[1]] {
+			retry = append(retry, pair[0])
+			continue
+		}
+		copy(pair[0].Ova, pair[1].Ova)
+		copy(pair[0].Oor, pair[1].Oor)
+		o.Cache.addHit()
+	}
+	if len(retry) > 0 {
+		nfeval += o.evaluate(retry, cpu)
+	}
+	return
+}
+
+// evaluateOne computes the objective values of one solution
+//  Note: cpu is the index passed to the objective function (shifted by cpuOffset); i.e. the index
+//        of a worker if Nworkers > 0
+func (o *Optimiser) evaluateOne(sol *Solution, cpu int) (err error) {
+	cpu += o.cpuOffset
+	switch {
+	case o.ObjFuncErr != nil:
+		err = o.ObjFuncErr(sol, cpu)
+	case o.ObjFunc != nil:
+		o.ObjFunc(sol, cpu)
+	default:
+		o.BatchObjFunc([]*Solution{sol}, cpu)
+	}
+	if err == nil {
+		err = checkNaN(sol)
+	}
+	return
+}
+
+// recover handles a failed evaluation according to FailPolicy
+//  Output:
+//   nfeval -- number of extra function evaluations due to re-sampling
+//   ok     -- a re-sampled solution has been successfully evaluated
+func (o *Optimiser) recover(sol *Solution, cpu int, err error) (nfeval int, ok bool) {
+	grp := o.Groups[cpu]
+	grp.nfailed++
+	switch o.FailPolicy {
+	case "abort":
+		if grp.err == nil {
+			grp.err = chk.Err("evaluation of solution %d failed:\n%v", sol.Id, err)
+		}
+	case "resample":
+		if sol.Fixed {
+			break
+		}
+		for k := 0; k < o.FailNresample; k++ {
+			o.resample(sol, grp.Rnd)
+			nfeval++
+			var wg sync.WaitGroup
+			o.submit(cpu, &wg, func(icpu int) {
+				err = o.evaluateOne(sol, icpu)
+			})
+			wg.Wait()
+			if err == nil {
+				return nfeval, true
+			}
+			grp.nfailed++
+		}
+	}
+	setInfeasible(sol)
+	return
+}
+
+// resample generates new random values for the floats and ints of sol
+func (o *Optimiser) resample(sol *Solution, rng *Rnd) {
+	for i := 0; i < o.Nflt; i++ {
+		sol.Flt[i] = rng.Float64(o.FltMin[i], o.FltMax[i])
+	}
+	for i := 0; i < o.Nint; i++ {
+		if o.BinInt > 0 {
+			sol.Int[i] = rng.Int(0, 1)
+		} else {
+			sol.Int[i] = rng.Int(o.IntMin[i], o.IntMax[i])
+		}
+	}
+}
+
+// collectFailures adds the number of failed evaluations in groups to Nfailed and returns the
+// error of the first group that has aborted, if any
+func (o *Optimiser) collectFailures() (err error) {
+	for _, grp := range o.Groups {
+		o.Nfailed += grp.nfailed
+		grp.nfailed = 0
+		if err == nil {
+			err = grp.err
+		}
+	}
+	return
+}
+
+// setOvaOor sets the objective and out-of-range values of sol using the results of MinProb
+func (o *Optimiser) setOvaOor(sol *Solution, cpu int) {
+	for i, f := range o.F[cpu] {
+		sol.Ova[i] = f
+	}
+	for i, g := range o.G[cpu] {
+		sol.Oor[i] = utl.GtePenalty(g, 0.0, 1) // g[i] ≥ 0
+	}
+	for i, h := range o.H[cpu] {
+		h = math.Abs(h)
+		sol.Ova[0] += h
+		sol.Oor[o.Ng+i] = utl.GtePenalty(o.EpsH, h, 1) // ϵ ≥ |h[i]|
+	}
+}
+
+// checkNaN returns an error if any objective or out-of-range value of sol is NaN
+func checkNaN(sol *Solution) error {
+	for i, v := range sol.Ova {
+		if math.IsNaN(v) {
+			return chk.Err("Ova[%d] is NaN", i)
+		}
+	}
+	for i, v := range sol.Oor {
+		if math.IsNaN(v) {
+			return chk.Err("Oor[%d] is NaN", i)
+		}
+	}
+	return nil
+}
+
+// setInfeasible sets the objective and out-of-range values of sol to the worst possible values
+func setInfeasible(sol *Solution) {
+	for i := range sol.Ova {
+		sol.Ova[i] = INF
+	}
+	for i := range sol.Oor {
+		sol.Oor[i] = INF
+	}
+}
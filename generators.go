@@ -11,6 +11,11 @@ import (
 
 // GenTrialSolutions generates (initial) trial solutions
 func GenTrialSolutions(sols []*Solution, prms *Parameters, reset bool) {
+	withDefaultRnd(func(rng *Rnd) { GenTrialSolutionsRng(sols, prms, reset, rng) })
+}
+
+// GenTrialSolutionsRng is the same as GenTrialSolutions but uses the given stream of random numbers
+func GenTrialSolutionsRng(sols []*Solution, prms *Parameters, reset bool, rng *Rnd) {
 
 	// reset solutions
 	if reset {
@@ -26,7 +31,7 @@ func GenTrialSolutions(sols []*Solution, prms *Parameters, reset bool) {
 		// interior points
 		switch prms.GenType {
 		case "latin":
-			K := rnd.LatinIHS(prms.Nflt, n, prms.LatinDup)
+			K := rng.LatinIHS(prms.Nflt, n, prms.LatinDup)
 			for i := 0; i < n; i++ {
 				for j := 0; j < prms.Nflt; j++ {
 					sols[i].Flt[j] = prms.FltMin[j] + float64(K[j][i]-1)*prms.DelFlt[j]/float64(n-1)
@@ -42,7 +47,7 @@ func GenTrialSolutions(sols []*Solution, prms *Parameters, reset bool) {
 		default:
 			for i := 0; i < n; i++ {
 				for j := 0; j < prms.Nflt; j++ {
-					sols[i].Flt[j] = rnd.Float64(prms.FltMin[j], prms.FltMax[j])
+					sols[i].Flt[j] = rng.Float64(prms.FltMin[j], prms.FltMax[j])
 				}
 			}
 		}
@@ -129,7 +134,7 @@ func GenTrialSolutions(sols []*Solution, prms *Parameters, reset bool) {
 	if prms.BinInt > 0 {
 		for i := 0; i < n; i++ {
 			for j := 0; j < prms.Nint; j++ {
-				if rnd.FlipCoin(0.5) {
+				if rng.FlipCoin(0.5) {
 					sols[i].Int[j] = 1
 				} else {
 					sols[i].Int[j] = 0
@@ -140,7 +145,7 @@ func GenTrialSolutions(sols []*Solution, prms *Parameters, reset bool) {
 	}
 
 	// general integers
-	L := rnd.LatinIHS(prms.Nint, n, prms.LatinDup)
+	L := rng.LatinIHS(prms.Nint, n, prms.LatinDup)
 	for i := 0; i < n; i++ {
 		for j := 0; j < prms.Nint; j++ {
 			sols[i].Int[j] = prms.IntMin[j] + (L[j][i]-1)*prms.DelInt[j]/(n-1)
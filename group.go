@@ -8,21 +8,37 @@ import "github.com/cpmech/gosl/utl"
 
 // Group holds a group of solutions
 type Group struct {
-	Ncur    int         // number of current solutions == len(All) / 2
-	All     []*Solution // current and future solutions. half part is a view to Solutions
-	Indices []int       // indices of current solutions
-	Pairs   [][]int     // randomly selected pairs from Indices
-	Metrics *Metrics    // metrics
+	Ncur       int         // number of current solutions == len(All) / 2
+	All        []*Solution // current and future solutions. half part is a view to Solutions
+	Indices    []int       // indices of current solutions
+	Pairs      [][]int     // randomly selected pairs from Indices
+	Offspring  []*Solution // future solutions created in the current generation. views to All
+	Metrics    *Metrics    // metrics
+	Rnd        *Rnd        // random numbers stream of this group
+	Prms       *Parameters // parameters of this group; i.e. modified by an Island
+	Nfeval     int         // number of function evaluations performed by this group
+	Noffspring int         // number of offspring that have competed with their parents
+	Nsuccess   int         // number of offspring that have replaced their parents
+	nfailed    int         // number of failed evaluations
+	err        error       // error of failed evaluation if FailPolicy == "abort"
+	deMem      *deMemory   // success-history memories of F and CR (only if DEadapt == "shade")
 }
 
 // Init initialises group
 func (o *Group) Init(cpu, ncpu int, solutions []*Solution, prms *Parameters) {
 	nsol := len(solutions)
 	start, endp1 := (cpu*nsol)/ncpu, ((cpu+1)*nsol)/ncpu
+	o.initRange(start, endp1, solutions, prms)
+}
+
+// initRange initialises group with solutions[start:endp1]
+func (o *Group) initRange(start, endp1 int, solutions []*Solution, prms *Parameters) {
+	nsol := len(solutions)
 	o.Ncur = endp1 - start
 	o.All = make([]*Solution, o.Ncur*2)
 	o.Indices = make([]int, o.Ncur)
 	o.Pairs = utl.IntAlloc(o.Ncur/2, 2)
+	o.Offspring = make([]*Solution, 0, o.Ncur)
 	for i := 0; i < o.Ncur; i++ {
 		o.All[i] = solutions[start+i]
 		o.All[o.Ncur+i] = NewSolution(-(1 + i), nsol, prms) // the index is for debugging
@@ -30,12 +46,22 @@ func (o *Group) Init(cpu, ncpu int, solutions []*Solution, prms *Parameters) {
 	}
 	o.Metrics = new(Metrics)
 	o.Metrics.Init(len(o.All), prms)
+	o.Prms = prms
+	o.deMem = nil
+	if prms.DEadapt == "shade" {
+		o.deMem = newDEmemory(prms.DEmem)
+	}
 }
 
 // Reset resets group data
 func (o *Group) Reset(cpu, ncpu int, solutions []*Solution) {
 	nsol := len(solutions)
 	start := (cpu * nsol) / ncpu
+	o.resetRange(start, solutions)
+}
+
+// resetRange resets group data with the solutions starting at start
+func (o *Group) resetRange(start int, solutions []*Solution) {
 	for i, pp := range o.Pairs {
 		for j, _ := range pp {
 			o.Pairs[i][j] = 0
@@ -45,4 +71,7 @@ func (o *Group) Reset(cpu, ncpu int, solutions []*Solution) {
 		o.All[i] = solutions[start+i]
 		o.All[o.Ncur+i].Reset(-(1 + i)) // there is no real need for this; but helps with debugging
 	}
+	if o.deMem != nil {
+		o.deMem.reset()
+	}
 }
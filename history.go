@@ -0,0 +1,326 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// HistoryData holds the state of the population at one exchange time
+//  Note: the statistics of objective values consider the feasible solutions only; they are INF if
+//        there are no feasible solutions. IGD is INF if Multi_fStar is not given; HV is INF if
+//        Multi_hvRef is not given; DEF and DEC are INF if DEadapt is not set
+type HistoryData struct {
+	Time     int           // time
+	Nfeval   int           // number of function evaluations
+	Elapsed  time.Duration // time elapsed since the beginning of the evolution
+	Fbest    []float64     // best value of each objective [nova]
+	Fmean    []float64     // mean value of each objective [nova]
+	Fworst   []float64     // worst value of each objective [nova]
+	Feasible float64       // fraction of feasible solutions
+	Nfront0  int           // number of feasible solutions in the first Pareto front
+	IGD      float64       // IGD metric with respect to Multi_fStar (only if Nova > 1)
+	HV       float64       // hypervolume of the feasible solutions with respect to Multi_hvRef
+	DEF      float64       // mean scale factor F of all solutions (self-adaptive differential evolution)
+	DEC      float64       // mean crossover rate CR of all solutions (self-adaptive differential evolution)
+}
+
+// History holds the convergence history of one run; i.e. one item per exchange time
+type History []*HistoryData
+
+// HistoryCurve holds the quartiles of one quantity over the trials of RunMany at each exchange
+type HistoryCurve struct {
+	Q1     []float64 // lower quartile [nexc]
+	Median []float64 // median [nexc]
+	Q3     []float64 // upper quartile [nexc]
+}
+
+// HistoryStat holds the convergence histories of all trials of RunMany aggregated at each exchange.
+// The trials stopped by a terminator do not contribute to the following exchanges
+type HistoryStat struct {
+	Time     []int           // time of each exchange [nexc]
+	Ntrials  []int           // number of trials with data at each exchange [nexc]
+	Nfeval   *HistoryCurve   // number of function evaluations
+	Elapsed  *HistoryCurve   // time elapsed since the beginning of the evolution in seconds
+	Fbest    []*HistoryCurve // best value of each objective [nova]
+	Fmean    []*HistoryCurve // mean value of each objective [nova]
+	Fworst   []*HistoryCurve // worst value of each objective [nova]
+	Feasible *HistoryCurve   // fraction of feasible solutions
+	Nfront0  *HistoryCurve   // number of feasible solutions in the first Pareto front
+	IGD      *HistoryCurve   // IGD metric
+	HV       *HistoryCurve   // hypervolume
+	DEF      *HistoryCurve   // mean scale factor F
+	DEC      *HistoryCurve   // mean crossover rate CR
+}
+
+// recordHistory records the state of the population at exchange time. Must be called after
+// Metrics.Compute(Solutions)
+func (o *Optimiser) recordHistory(tcur int) {
+	if !o.RecHistory {
+		return
+	}
+	h := &HistoryData{
+		Time:    tcur,
+		Nfeval:  o.Nfeval,
+		Elapsed: time.Now().Sub(o.tstart),
+		Fbest:   make([]float64, o.Nova),
+		Fmean:   make([]float64, o.Nova),
+		Fworst:  make([]float64, o.Nova),
+		IGD:     INF,
+		HV:      INF,
+	}
+	var feasible [][]float64
+	for _, sol := range o.Solutions {
+		if sol.Feasible() {
+			feasible = append(feasible, sol.Ova)
+			if sol.FrontId == 0 {
+				h.Nfront0++
+			}
+		}
+	}
+	h.Feasible = float64(len(feasible)) / float64(len(o.Solutions))
+	for i := 0; i < o.Nova; i++ {
+		h.Fbest[i], h.Fmean[i], h.Fworst[i] = INF, INF, INF
+		if len(feasible) == 0 {
+			continue
+		}
+		h.Fbest[i], h.Fmean[i], h.Fworst[i] = INF, 0, -INF
+		for _, ova := range feasible {
+			if ova[i] < h.Fbest[i] {
+				h.Fbest[i] = ova[i]
+			}
+			if ova[i] > h.Fworst[i] {
+				h.Fworst[i] = ova[i]
+			}
+			h.Fmean[i] += ova[i]
+		}
+		h.Fmean[i] /= float64(len(feasible))
+	}
+	if o.Nova > 1 && len(o.Multi_fStar) > 0 {
+		h.IGD = o.calcIgd(o.Multi_fStar)
+	}
+	if len(o.Multi_hvRef) == o.Nova {
+		h.HV = hypervolume(feasible, o.Multi_hvRef)
+	}
+	h.DEF, h.DEC = o.meanDEcontrol()
+	o.History = append(o.History, h)
+}
+
+// WriteCSV writes the convergence history to a CSV file with one row per exchange time. The
+// elapsed time is written in seconds
+func (o History) WriteCSV(path string) (err error) {
+	var buf bytes.Buffer
+	if len(o) == 0 {
+		return writeHistory(path, buf.Bytes())
+	}
+	nova := len(o[0].Fbest)
+	io.Ff(&buf, "time,nfeval,elapsed")
+	for _, key := range []string{"fbest", "fmean", "fworst"} {
+		for i := 0; i < nova; i++ {
+			io.Ff(&buf, ",%s%d", key, i)
+		}
+	}
+	io.Ff(&buf, ",feasible,nfront0,igd,hv,def,dec\n")
+	for _, h := range o {
+		io.Ff(&buf, "%d,%d,%g", h.Time, h.Nfeval, h.Elapsed.Seconds())
+		for _, f := range [][]float64{h.Fbest, h.Fmean, h.Fworst} {
+			for i := 0; i < nova; i++ {
+				io.Ff(&buf, ",%g", f[i])
+			}
+		}
+		io.Ff(&buf, ",%g,%d,%g,%g,%g,%g\n", h.Feasible, h.Nfront0, h.IGD, h.HV, h.DEF, h.DEC)
+	}
+	return writeHistory(path, buf.Bytes())
+}
+
+// WriteJSON writes the convergence history to a JSON file. The elapsed time is written in
+// nanoseconds
+func (o History) WriteJSON(path string) (err error) {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return chk.Err("cannot marshal history:\n%v", err)
+	}
+	return writeHistory(path, b)
+}
+
+// writeHistory writes b to file
+func writeHistory(path string, b []byte) (err error) {
+	err = ioutil.WriteFile(path, b, 0644)
+	if err != nil {
+		return chk.Err("cannot write history file %q:\n%v", path, err)
+	}
+	return
+}
+
+// newHistoryStat aggregates the convergence histories of the trials of RunMany
+func newHistoryStat(hists []History, nova int) (o *HistoryStat) {
+	nexc := 0
+	for _, hist := range hists {
+		if len(hist) > nexc {
+			nexc = len(hist)
+		}
+	}
+	o = new(HistoryStat)
+	o.Time = make([]int, nexc)
+	o.Ntrials = make([]int, nexc)
+	for k := 0; k < nexc; k++ {
+		for _, hist := range hists {
+			if k < len(hist) {
+				o.Time[k] = hist[k].Time
+				o.Ntrials[k]++
+			}
+		}
+	}
+	curve := func(value func(h *HistoryData) float64) (c *HistoryCurve) {
+		c = &HistoryCurve{make([]float64, nexc), make([]float64, nexc), make([]float64, nexc)}
+		for k := 0; k < nexc; k++ {
+			var values []float64
+			for _, hist := range hists {
+				if k < len(hist) {
+					values = append(values, value(hist[k]))
+				}
+			}
+			c.Q1[k], c.Median[k], c.Q3[k] = quartiles(values)
+		}
+		return
+	}
+	o.Nfeval = curve(func(h *HistoryData) float64 { return float64(h.Nfeval) })
+	o.Elapsed = curve(func(h *HistoryData) float64 { return h.Elapsed.Seconds() })
+	o.Fbest = make([]*HistoryCurve, nova)
+	o.Fmean = make([]*HistoryCurve, nova)
+	o.Fworst = make([]*HistoryCurve, nova)
+	for i := 0; i < nova; i++ {
+		j := i
+		o.Fbest[i] = curve(func(h *HistoryData) float64 { return h.Fbest[j] })
+		o.Fmean[i] = curve(func(h *HistoryData) float64 { return h.Fmean[j] })
+		o.Fworst[i] = curve(func(h *HistoryData) float64 { return h.Fworst[j] })
+	}
+	o.Feasible = curve(func(h *HistoryData) float64 { return h.Feasible })
+	o.Nfront0 = curve(func(h *HistoryData) float64 { return float64(h.Nfront0) })
+	o.IGD = curve(func(h *HistoryData) float64 { return h.IGD })
+	o.HV = curve(func(h *HistoryData) float64 { return h.HV })
+	o.DEF = curve(func(h *HistoryData) float64 { return h.DEF })
+	o.DEC = curve(func(h *HistoryData) float64 { return h.DEC })
+	return
+}
+
+// WriteCSV writes the aggregated convergence history to a CSV file with one row per exchange and
+// three columns (q1, median and q3) per quantity
+func (o *HistoryStat) WriteCSV(path string) (err error) {
+	var buf bytes.Buffer
+	keys, curves := o.curves()
+	io.Ff(&buf, "time,ntrials")
+	for _, key := range keys {
+		io.Ff(&buf, ",%s_q1,%s_median,%s_q3", key, key, key)
+	}
+	io.Ff(&buf, "\n")
+	for k := range o.Time {
+		io.Ff(&buf, "%d,%d", o.Time[k], o.Ntrials[k])
+		for _, c := range curves {
+			io.Ff(&buf, ",%g,%g,%g", c.Q1[k], c.Median[k], c.Q3[k])
+		}
+		io.Ff(&buf, "\n")
+	}
+	return writeHistory(path, buf.Bytes())
+}
+
+// WriteJSON writes the aggregated convergence history to a JSON file
+func (o *HistoryStat) WriteJSON(path string) (err error) {
+	b, err := json.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return chk.Err("cannot marshal history:\n%v", err)
+	}
+	return writeHistory(path, b)
+}
+
+// curves returns the names and curves of all quantities
+func (o *HistoryStat) curves() (keys []string, curves []*HistoryCurve) {
+	keys = []string{"nfeval", "elapsed"}
+	curves = []*HistoryCurve{o.Nfeval, o.Elapsed}
+	for _, c := range []struct {
+		key    string
+		curves []*HistoryCurve
+	}{{"fbest", o.Fbest}, {"fmean", o.Fmean}, {"fworst", o.Fworst}} {
+		for i, curve := range c.curves {
+			keys = append(keys, io.Sf("%s%d", c.key, i))
+			curves = append(curves, curve)
+		}
+	}
+	keys = append(keys, "feasible", "nfront0", "igd", "hv", "def", "dec")
+	curves = append(curves, o.Feasible, o.Nfront0, o.IGD, o.HV, o.DEF, o.DEC)
+	return
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// quartiles computes the lower quartile, median and upper quartile of values using linear
+// interpolation between the sorted values
+func quartiles(values []float64) (q1, median, q3 float64) {
+	if len(values) == 0 {
+		return INF, INF, INF
+	}
+	v := append([]float64{}, values...)
+	sort.Float64s(v)
+	quantile := func(p float64) float64 {
+		x := p * float64(len(v)-1)
+		i := int(x)
+		if i+1 >= len(v) {
+			return v[len(v)-1]
+		}
+		return v[i] + (x-float64(i))*(v[i+1]-v[i])
+	}
+	return quantile(0.25), quantile(0.5), quantile(0.75)
+}
+
+// hypervolume computes the hypervolume dominated by points with respect to the reference point ref
+// (minimisation). The points that do not dominate ref are ignored
+//  Note: using the recursive slicing method; i.e. slices along the last objective
+func hypervolume(points [][]float64, ref []float64) (hv float64) {
+	m := len(ref)
+	var pts [][]float64
+	for _, p := range points {
+		inside := true
+		for j := 0; j < m; j++ {
+			if p[j] >= ref[j] {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			pts = append(pts, p)
+		}
+	}
+	if len(pts) == 0 {
+		return 0
+	}
+	if m == 1 {
+		fmin := ref[0]
+		for _, p := range pts {
+			if p[0] < fmin {
+				fmin = p[0]
+			}
+		}
+		return ref[0] - fmin
+	}
+	sort.Slice(pts, func(i, j int) bool { return pts[i][m-1] < pts[j][m-1] })
+	for i := range pts {
+		upper := ref[m-1]
+		if i+1 < len(pts) {
+			upper = pts[i+1][m-1]
+		}
+		depth := upper - pts[i][m-1]
+		if depth > 0 {
+			hv += depth * hypervolume(pts[:i+1], ref[:m-1])
+		}
+	}
+	return
+}
@@ -8,7 +8,6 @@ import (
 	"sort"
 
 	"github.com/cpmech/gosl/chk"
-	"github.com/cpmech/gosl/rnd"
 )
 
 // CxInt performs the crossover of genetic data from A and B
@@ -23,14 +22,19 @@ import (
 //     a = a . . . . f g h
 //     b = * b c d e * * *
 func CxInt(a, b, A, B []int, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { CxIntRng(a, b, A, B, prms, rng) })
+}
+
+// CxIntRng is the same as CxInt but uses the given stream of random numbers
+func CxIntRng(a, b, A, B []int, prms *Parameters, rng *Rnd) {
 	size := len(A)
-	if !rnd.FlipCoin(prms.IntPc) || size < 2 {
+	if !rng.FlipCoin(prms.IntPc) || size < 2 {
 		for i := 0; i < len(A); i++ {
 			a[i], b[i] = A[i], B[i]
 		}
 		return
 	}
-	ends := GenerateCxEnds(size, prms.IntNcuts, nil)
+	ends := GenerateCxEndsRng(size, prms.IntNcuts, nil, rng)
 	swap := false
 	start := 0
 	for _, end := range ends {
@@ -76,8 +80,13 @@ func CxInt(a, b, A, B []int, prms *Parameters) {
 //     a = d e | f h g | a b c         get from A: | f̶ g̶ h̶ | a b | c d e
 //     b = h g | c d e | a b f         get from B: | e̶ c̶ a | b d̶ | f h g
 func CxIntOrd(a, b, A, B []int, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { CxIntOrdRng(a, b, A, B, prms, rng) })
+}
+
+// CxIntOrdRng is the same as CxIntOrd but uses the given stream of random numbers
+func CxIntOrdRng(a, b, A, B []int, prms *Parameters, rng *Rnd) {
 	size := len(A)
-	if !rnd.FlipCoin(prms.IntPc) || size < 3 {
+	if !rng.FlipCoin(prms.IntPc) || size < 3 {
 		for i := 0; i < len(A); i++ {
 			a[i], b[i] = A[i], B[i]
 		}
@@ -88,8 +97,8 @@ func CxIntOrd(a, b, A, B []int, prms *Parameters) {
 	if len(cuts) == 2 {
 		s, t = cuts[0], cuts[1]
 	} else {
-		s = rnd.Int(1, size-2)
-		t = rnd.Int(s+1, size-1)
+		s = rng.Int(1, size-2)
+		t = rng.Int(s+1, size-1)
 	}
 	chk.IntAssertLessThan(s, t)
 	acore := B[s:t]
@@ -129,15 +138,20 @@ func CxIntOrd(a, b, A, B []int, prms *Parameters) {
 // MtInt performs the mutation of genetic data from A
 //  Output: modified individual 'A'
 func MtInt(A []int, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { MtIntRng(A, prms, rng) })
+}
+
+// MtIntRng is the same as MtInt but uses the given stream of random numbers
+func MtIntRng(A []int, prms *Parameters, rng *Rnd) {
 	size := len(A)
-	if !rnd.FlipCoin(prms.IntPm) || size < 1 {
+	if !rng.FlipCoin(prms.IntPm) || size < 1 {
 		return
 	}
 	mmax := 2
-	pos := rnd.IntGetUniqueN(0, size, prms.IntNchanges)
+	pos := rng.IntGetUniqueN(0, size, prms.IntNchanges)
 	for _, i := range pos {
-		m := rnd.Int(1, mmax)
-		if rnd.FlipCoin(0.5) {
+		m := rng.Int(1, mmax)
+		if rng.FlipCoin(0.5) {
 			A[i] += m * A[i]
 		} else {
 			A[i] -= m * A[i]
@@ -148,11 +162,16 @@ func MtInt(A []int, prms *Parameters) {
 // MtIntBin performs the mutation of a binary chromosome
 //  Output: modified individual 'A'
 func MtIntBin(A []int, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { MtIntBinRng(A, prms, rng) })
+}
+
+// MtIntBinRng is the same as MtIntBin but uses the given stream of random numbers
+func MtIntBinRng(A []int, prms *Parameters, rng *Rnd) {
 	size := len(A)
-	if !rnd.FlipCoin(prms.IntPm) || size < 1 {
+	if !rng.FlipCoin(prms.IntPm) || size < 1 {
 		return
 	}
-	pos := rnd.IntGetUniqueN(0, size, prms.IntNchanges)
+	pos := rng.IntGetUniqueN(0, size, prms.IntNchanges)
 	for _, i := range pos {
 		if A[i] == 0 {
 			A[i] = 1
@@ -187,8 +206,13 @@ func MtIntBin(A []int, prms *Parameters) {
 //                       ↑
 //                       4 = ins
 func MtIntOrd(A []int, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { MtIntOrdRng(A, prms, rng) })
+}
+
+// MtIntOrdRng is the same as MtIntOrd but uses the given stream of random numbers
+func MtIntOrdRng(A []int, prms *Parameters, rng *Rnd) {
 	size := len(A)
-	if !rnd.FlipCoin(prms.IntPm) || size < 3 {
+	if !rng.FlipCoin(prms.IntPm) || size < 3 {
 		if size == 2 {
 			A[0], A[1] = A[1], A[0]
 		}
@@ -201,11 +225,11 @@ func MtIntOrd(A []int, prms *Parameters) {
 		ncore = t - s
 		nrem = size - ncore
 	} else {
-		s = rnd.Int(1, size-2)
-		t = rnd.Int(s+1, size-1)
+		s = rng.Int(1, size-2)
+		t = rng.Int(s+1, size-1)
 		ncore = t - s
 		nrem = size - ncore
-		ins = rnd.Int(1, nrem)
+		ins = rng.Int(1, nrem)
 	}
 	core := make([]int, ncore)
 	remain := make([]int, nrem)
@@ -251,6 +275,12 @@ func MtIntOrd(A []int, prms *Parameters) {
 //         ↑       ↑     ↑   cuts = [1, 5]
 //         1       5     8   ends = [1, 5, 8]
 func GenerateCxEnds(size, ncuts int, cuts []int) (ends []int) {
+	withDefaultRnd(func(rng *Rnd) { ends = GenerateCxEndsRng(size, ncuts, cuts, rng) })
+	return
+}
+
+// GenerateCxEndsRng is the same as GenerateCxEnds but uses the given stream of random numbers
+func GenerateCxEndsRng(size, ncuts int, cuts []int, rng *Rnd) (ends []int) {
 
 	// handle small slices
 	if size < 2 {
@@ -291,7 +321,7 @@ func GenerateCxEnds(size, ncuts int, cuts []int) (ends []int) {
 	ends[ncuts] = size
 
 	// pool of values for selections
-	pool := rnd.IntGetUniqueN(1, size, ncuts)
+	pool := rng.IntGetUniqueN(1, size, ncuts)
 	sort.Ints(pool)
 	for i := 0; i < ncuts; i++ {
 		ends[i] = pool[i]
@@ -0,0 +1,229 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"bytes"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// Island holds the parameters of one group (island). Zero Nsol, empty strings, nil pointers and
+// nil functions mean that the values in Parameters and the functions in Optimiser are used. The
+// pointers allow zero values to be set; e.g. IntPm: new(float64) disables the mutation of ints
+type Island struct {
+	Nsol        int        // number of solutions in this group. either all or none of the islands must set Nsol
+	FltOp       string     // operator for floats: "de" or "sbx"; unless CxFlt is given
+	DEF         *float64   // scale factor F for differential evolution; zero means random F
+	DEC         *float64   // C-coefficient for differential evolution
+	DEstrategy  string     // mutation strategy of differential evolution
+	FltPc       *float64   // probability of SBX crossover of floats
+	FltPm       *float64   // probability of polynomial mutation of floats; zero means 1/Nflt
+	IntPc       *float64   // probability of crossover for ints
+	IntNcuts    *int       // number of cuts in crossover of ints
+	IntPm       *float64   // probability of mutation for ints
+	IntNchanges *int       // number of changes during mutation of ints
+	CxInt       CxInt_t    // crossover function for ints
+	MtInt       MtInt_t    // mutation function for ints
+	CxIntRng    CxIntRng_t // crossover function for ints using the stream of the group; instead of CxInt
+	MtIntRng    MtIntRng_t // mutation function for ints using the stream of the group; instead of MtInt
+	CxFlt       CxFlt_t    // crossover function for floats
+	MtFlt       MtFlt_t    // mutation function for floats
+}
+
+// IslandStat holds statistics of one group (island)
+type IslandStat struct {
+	Nsol       int     // number of solutions
+	Nfeval     int     // number of function evaluations
+	Noffspring int     // number of offspring that have competed with their parents
+	Nsuccess   int     // number of offspring that have replaced their parents
+	Nfeasible  int     // number of feasible solutions
+	Ova0       float64 // best feasible ova[0]; INF if there are no feasible solutions
+}
+
+// IslandStats returns statistics of each group (island) since the last Init or Reset
+func (o *Optimiser) IslandStats() (res []*IslandStat) {
+	res = make([]*IslandStat, len(o.Groups))
+	for cpu, grp := range o.Groups {
+		res[cpu] = &IslandStat{Nsol: grp.Ncur, Nfeval: grp.Nfeval, Noffspring: grp.Noffspring, Nsuccess: grp.Nsuccess, Ova0: INF}
+		for _, sol := range grp.All[:grp.Ncur] {
+			if sol.Feasible() {
+				res[cpu].Nfeasible++
+				if sol.Ova[0] < res[cpu].Ova0 {
+					res[cpu].Ova0 = sol.Ova[0]
+				}
+			}
+		}
+	}
+	return
+}
+
+// PrintStatIslands prints statistics of each group (island)
+func (o *Optimiser) PrintStatIslands() {
+	var buf bytes.Buffer
+	io.Ff(&buf, "%6s%8s%10s%8s%10s%24s\n", "island", "nsol", "nfeval", "success", "nfeasible", "best ova[0]")
+	for cpu, s := range o.IslandStats() {
+		success := 0.0
+		if s.Noffspring > 0 {
+			success = float64(s.Nsuccess) / float64(s.Noffspring)
+		}
+		io.Ff(&buf, "%6d%8d%10d%8.3f%10d%24g\n", cpu, s.Nsol, s.Nfeval, success, s.Nfeasible, s.Ova0)
+	}
+	io.Pf("%s", buf.String())
+}
+
+// checkIslands checks the consistency of Islands, after CalcDerived has been called
+func (o *Optimiser) checkIslands() (err error) {
+	if o.Islands == nil {
+		return
+	}
+	if len(o.Islands) != o.Ncpu {
+		return chk.Err("number of islands must be equal to Ncpu = %d. %d is invalid", o.Ncpu, len(o.Islands))
+	}
+	nsol, nset := 0, 0
+	for i, isl := range o.Islands {
+		if isl == nil {
+			return chk.Err("island %d must not be nil", i)
+		}
+		if isl.Nsol != 0 {
+			if isl.Nsol < 2 {
+				return chk.Err("number of solutions of island %d must be greater than or equal to 2. Nsol = %d is invalid", i, isl.Nsol)
+			}
+			nsol += isl.Nsol
+			nset++
+		}
+	}
+	if nset > 0 && nset != o.Ncpu {
+		return chk.Err("either all or none of the islands must set Nsol")
+	}
+	if nset > 0 && nsol != o.Nsol {
+		return chk.Err("sum of number of solutions of islands must be equal to Nsol = %d. %d is invalid", o.Nsol, nsol)
+	}
+	for i := range o.Islands {
+		if err = o.groupParams(i).Validate(); err != nil {
+			return chk.Err("parameters of island %d are invalid:\n%v", i, err)
+		}
+	}
+	return
+}
+
+// groupRange returns the range of indices in Solutions of the current solutions of one group. The
+// solutions are equally distributed unless Islands set Nsol. The ranges are scaled if Nsol has
+// grown due to restarts
+func (o *Optimiser) groupRange(cpu int) (start, endp1 int) {
+	return o.groupRangeN(cpu, o.Nsol)
+}
+
+// groupRangeN returns the range of indices of one group as groupRange does if there were nsol
+// solutions
+func (o *Optimiser) groupRangeN(cpu, nsol int) (start, endp1 int) {
+	if o.Islands == nil || o.Islands[0].Nsol == 0 {
+		return (cpu * nsol) / o.Ncpu, ((cpu + 1) * nsol) / o.Ncpu
+	}
+	cum := 0
+	for i := 0; i < cpu; i++ {
+		cum += o.Islands[i].Nsol
+	}
+	start = (cum * nsol) / o.nsol0
+	endp1 = ((cum + o.Islands[cpu].Nsol) * nsol) / o.nsol0
+	return
+}
+
+// groupParams returns the parameters of one group; i.e. a copy of Parameters modified by Islands
+func (o *Optimiser) groupParams(cpu int) (prms *Parameters) {
+	if o.Islands == nil {
+		return &o.Parameters
+	}
+	isl := o.Islands[cpu]
+	p := o.Parameters
+	if isl.FltOp != "" {
+		p.FltOp = isl.FltOp
+	}
+	if isl.DEF != nil {
+		p.DEF = *isl.DEF
+	}
+	if isl.DEC != nil {
+		p.DEC = *isl.DEC
+	}
+	if isl.DEstrategy != "" {
+		p.DEstrategy = isl.DEstrategy
+	}
+	if isl.FltPc != nil {
+		p.FltPc = *isl.FltPc
+	}
+	if isl.FltPm != nil {
+		p.FltPm = *isl.FltPm
+	}
+	if isl.IntPc != nil {
+		p.IntPc = *isl.IntPc
+	}
+	if isl.IntNcuts != nil {
+		p.IntNcuts = *isl.IntNcuts
+	}
+	if isl.IntPm != nil {
+		p.IntPm = *isl.IntPm
+	}
+	if isl.IntNchanges != nil {
+		p.IntNchanges = *isl.IntNchanges
+	}
+	return &p
+}
+
+// groupOperators returns the crossover and mutation functions for ints of one group. The built-in
+// functions without stream (e.g. CxInt) are replaced by their versions with stream (e.g. CxIntRng);
+// other functions without stream are wrapped and draw from their own source
+func (o *Optimiser) groupOperators(cpu int) (cxInt CxIntRng_t, mtInt MtIntRng_t) {
+	cx, mt := o.CxInt, o.MtInt
+	cxInt, mtInt = o.CxIntRng, o.MtIntRng
+	if o.Islands != nil {
+		isl := o.Islands[cpu]
+		if isl.CxIntRng != nil {
+			cxInt = isl.CxIntRng
+		} else if isl.CxInt != nil {
+			cxInt, cx = nil, isl.CxInt
+		}
+		if isl.MtIntRng != nil {
+			mtInt = isl.MtIntRng
+		} else if isl.MtInt != nil {
+			mtInt, mt = nil, isl.MtInt
+		}
+	}
+	if cxInt == nil && cx != nil {
+		cxInt = builtinCxInt(cx)
+		if cxInt == nil {
+			cxInt = func(a, b, A, B []int, prms *Parameters, rng *Rnd) { cx(a, b, A, B, prms) }
+		}
+	}
+	if mtInt == nil && mt != nil {
+		mtInt = builtinMtInt(mt)
+		if mtInt == nil {
+			mtInt = func(a []int, prms *Parameters, rng *Rnd) { mt(a, prms) }
+		}
+	}
+	return
+}
+
+// groupFltOperators returns the crossover and mutation functions for floats of one group. If no
+// crossover function is given, the FltOp of the group (see groupParams) selects SBX (CxFltSBX and
+// MtFltPoly) or differential evolution (nil crossover function)
+func (o *Optimiser) groupFltOperators(cpu int, prms *Parameters) (cxFlt CxFlt_t, mtFlt MtFlt_t) {
+	cxFlt, mtFlt = o.CxFlt, o.MtFlt
+	if o.Islands != nil {
+		if o.Islands[cpu].CxFlt != nil {
+			cxFlt = o.Islands[cpu].CxFlt
+		}
+		if o.Islands[cpu].MtFlt != nil {
+			mtFlt = o.Islands[cpu].MtFlt
+		}
+	}
+	if cxFlt == nil && prms.FltOp == "sbx" {
+		cxFlt = CxFltSBX
+		if mtFlt == nil {
+			mtFlt = MtFltPoly
+		}
+	}
+	return
+}
@@ -0,0 +1,119 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/gm/tri"
+)
+
+// Mesh holds the Delaunay triangulation of (xi,xj) points. The vertices have the same indices as
+// the points used to build the mesh; e.g. the indices of Solutions
+type Mesh struct {
+	V      [][]float64 // vertices
+	C      [][]int     // cells
+	Vcells [][]int     // [nverts] cells sharing each vertex
+	Area   []float64   // [ncells] area of each cell
+}
+
+// NewMesh computes the Delaunay triangulation of (X[k],Y[k]) points
+func NewMesh(X, Y []float64) (o *Mesh) {
+	o = new(Mesh)
+	o.V, o.C = tri.Delaunay(X, Y, false)
+	o.Vcells = make([][]int, len(o.V))
+	o.Area = make([]float64, len(o.C))
+	for c, cell := range o.C {
+		a, b, d := o.V[cell[0]], o.V[cell[1]], o.V[cell[2]]
+		o.Area[c] = math.Abs((b[0]-a[0])*(d[1]-a[1])-(d[0]-a[0])*(b[1]-a[1])) / 2.0
+		for _, v := range cell {
+			o.Vcells[v] = append(o.Vcells[v], c)
+		}
+	}
+	return
+}
+
+// RandomPointAround generates a random point in one of the cells sharing vertex v. The cells are
+// selected with probability proportional to their area
+//  Output:
+//   x, y -- coordinates of point
+//   ok   -- false if v does not belong to any cell with non-zero area
+func (o *Mesh) RandomPointAround(v int, rng *Rnd) (x, y float64, ok bool) {
+
+	// select cell
+	if v < 0 || v >= len(o.Vcells) {
+		return
+	}
+	total := 0.0
+	for _, c := range o.Vcells[v] {
+		total += o.Area[c]
+	}
+	if total <= 0 {
+		return
+	}
+	r := rng.Float64(0, total)
+	cell := o.C[o.Vcells[v][len(o.Vcells[v])-1]]
+	for _, c := range o.Vcells[v] {
+		if r < o.Area[c] {
+			cell = o.C[c]
+			break
+		}
+		r -= o.Area[c]
+	}
+
+	// point in triangle
+	a, b, d := o.V[cell[0]], o.V[cell[1]], o.V[cell[2]]
+	s, t := rng.Float64(0, 1), rng.Float64(0, 1)
+	if s+t > 1 {
+		s, t = 1-s, 1-t
+	}
+	x = a[0] + s*(b[0]-a[0]) + t*(d[0]-a[0])
+	y = a[1] + s*(b[1]-a[1]) + t*(d[1]-a[1])
+	return x, y, true
+}
+
+// meshes //////////////////////////////////////////////////////////////////////////////////////////
+
+// buildMeshes computes the Delaunay triangulations of the (xi,xj) points of all solutions
+func (o *Optimiser) buildMeshes() {
+	if o.Nflt < 2 || !o.UseMesh {
+		return
+	}
+	for k, s := range o.Solutions { // Solutions may have been sorted; e.g. in RunMany
+		o.solIndex[s] = k
+	}
+	Xi, Xj := make([]float64, o.Nsol), make([]float64, o.Nsol)
+	o.Meshes = make([][]*Mesh, o.Nflt-1)
+	for i := 0; i < o.Nflt-1; i++ {
+		o.Meshes[i] = make([]*Mesh, o.Nflt)
+		for k, s := range o.Solutions {
+			Xi[k] = s.Flt[i]
+		}
+		for j := i + 1; j < o.Nflt; j++ {
+			for k, s := range o.Solutions {
+				Xj[k] = s.Flt[j]
+			}
+			o.Meshes[i][j] = NewMesh(Xi, Xj)
+		}
+	}
+}
+
+// meshMove moves, with probability MeshPm, the (xi,xj) coordinates of an offspring to a random
+// point in the cells around its parent in the mesh of a randomly selected (i,j) pair
+func (o *Optimiser) meshMove(x []float64, parent *Solution, rng *Rnd) {
+	if o.Meshes == nil || !rng.FlipCoin(o.MeshPm) {
+		return
+	}
+	v, ok := o.solIndex[parent]
+	if !ok {
+		return
+	}
+	i := rng.Int(0, o.Nflt-2)
+	j := rng.Int(i+1, o.Nflt-1)
+	xi, xj, ok := o.Meshes[i][j].RandomPointAround(v, rng)
+	if ok {
+		x[i], x[j] = xi, xj
+	}
+}
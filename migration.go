@@ -0,0 +1,224 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Migration exchanges solutions between groups (islands) at exchange times
+type Migration interface {
+	Init(opt *Optimiser) error                     // checks data; called by Init
+	Migrate(opt *Optimiser) (migrated []*Solution) // returns the solutions that have received data
+}
+
+// Migrator implements Migration with common topologies and policies. Migrants are copied from the
+// source group to the destination group along each link of the topology. Protected solutions are
+// never replaced
+//  Topology:
+//   "ring"   -- group i sends to group (i+1)%Ncpu
+//   "full"   -- every group sends to all other groups
+//   "star"   -- group 0 sends to and receives from all other groups
+//   "random" -- each link i→j exists with probability Prob; drawn at every migration
+//  Select (migrants):
+//   "best"   -- best solutions sorted by feasibility, front and ova[0]
+//   "random" -- random solutions
+//   "front0" -- random solutions of the first front; fewer than Rate may be sent
+//  Replace (solutions of the destination group):
+//   "worst"      -- worst solutions sorted by feasibility, front and ova[0]
+//   "random"     -- random solutions
+//   "tournament" -- random solutions if the migrant wins the fight against them
+type Migrator struct {
+	Topology string  // "ring", "full", "star" or "random"
+	Select   string  // selection of migrants: "best", "random" or "front0"
+	Replace  string  // replacement policy: "worst", "random" or "tournament"
+	Rate     int     // number of migrants sent along each link; zero means 1
+	Interval int     // number of exchange periods between migrations; zero means 1
+	Prob     float64 // probability of each link if Topology == "random"; zero means 0.5
+}
+
+// Init checks data
+func (o *Migrator) Init(opt *Optimiser) error {
+	switch o.Topology {
+	case "ring", "full", "star", "random":
+	default:
+		return chk.Err("topology of migration must be \"ring\", \"full\", \"star\" or \"random\". Topology = %q is invalid", o.Topology)
+	}
+	switch o.Select {
+	case "best", "random", "front0":
+	default:
+		return chk.Err("selection of migrants must be \"best\", \"random\" or \"front0\". Select = %q is invalid", o.Select)
+	}
+	switch o.Replace {
+	case "worst", "random", "tournament":
+	default:
+		return chk.Err("replacement policy of migration must be \"worst\", \"random\" or \"tournament\". Replace = %q is invalid", o.Replace)
+	}
+	if o.Rate < 0 || o.Interval < 0 || o.Prob < 0 || o.Prob > 1 {
+		return chk.Err("rate, interval and probability of migration must be non-negative (and Prob ≤ 1). Rate = %d, Interval = %d and Prob = %g are invalid", o.Rate, o.Interval, o.Prob)
+	}
+	return nil
+}
+
+// Migrate exchanges solutions between groups every Interval exchange periods
+func (o *Migrator) Migrate(opt *Optimiser) (migrated []*Solution) {
+
+	// interval. iova0 is the index of the current exchange period
+	interval := o.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	if (opt.iova0+1)%interval != 0 {
+		return
+	}
+
+	// select all migrants before replacing; thus a solution migrates at most once
+	rng := opt.Rnd
+	links := o.links(opt.Ncpu, rng)
+	migrants := make([][]*Solution, len(links))
+	for k, link := range links {
+		src := opt.Groups[link[0]]
+		for _, sol := range o.selectMigrants(src.All[:src.Ncur], rng) {
+			migrants[k] = append(migrants[k], snapshot(sol))
+		}
+	}
+
+	// replace solutions of destination groups
+	unique := make(map[*Solution]bool)
+	for k, link := range links {
+		dst := opt.Groups[link[1]]
+		candidates := o.candidates(dst.All[:dst.Ncur], rng)
+		for _, m := range migrants[k] {
+			if len(candidates) == 0 {
+				break
+			}
+			sol := candidates[0]
+			candidates = candidates[1:]
+			if o.Replace == "tournament" && !m.FightRng(sol, rng) {
+				continue
+			}
+			m.CopyInto(sol)
+			if !unique[sol] {
+				unique[sol] = true
+				migrated = append(migrated, sol)
+			}
+		}
+	}
+	return
+}
+
+// links returns the links (source, destination) of the topology
+func (o *Migrator) links(ncpu int, rng *Rnd) (links [][2]int) {
+	for i := 0; i < ncpu; i++ {
+		for j := 0; j < ncpu; j++ {
+			if i == j {
+				continue
+			}
+			switch o.Topology {
+			case "ring":
+				if j == (i+1)%ncpu {
+					links = append(links, [2]int{i, j})
+				}
+			case "full":
+				links = append(links, [2]int{i, j})
+			case "star":
+				if i == 0 || j == 0 {
+					links = append(links, [2]int{i, j})
+				}
+			case "random":
+				prob := o.Prob
+				if prob == 0 {
+					prob = 0.5
+				}
+				if rng.FlipCoin(prob) {
+					links = append(links, [2]int{i, j})
+				}
+			}
+		}
+	}
+	return
+}
+
+// selectMigrants selects Rate solutions to be sent to another group
+func (o *Migrator) selectMigrants(sols []*Solution, rng *Rnd) (res []*Solution) {
+	rate := o.Rate
+	if rate < 1 {
+		rate = 1
+	}
+	switch o.Select {
+	case "best":
+		res = sortedSolutions(sols)
+	case "random":
+		res = shuffledSolutions(sols, rng)
+	case "front0":
+		for _, sol := range shuffledSolutions(sols, rng) {
+			if sol.FrontId == 0 {
+				res = append(res, sol)
+			}
+		}
+	}
+	if len(res) > rate {
+		res = res[:rate]
+	}
+	return
+}
+
+// candidates returns the solutions that may be replaced by migrants in order of replacement
+func (o *Migrator) candidates(sols []*Solution, rng *Rnd) (res []*Solution) {
+	var free []*Solution
+	for _, sol := range sols {
+		if !sol.Protected() {
+			free = append(free, sol)
+		}
+	}
+	if o.Replace == "worst" {
+		res = sortedSolutions(free)
+		for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+			res[i], res[j] = res[j], res[i]
+		}
+		return
+	}
+	return shuffledSolutions(free, rng)
+}
+
+// sortedSolutions returns a copy of the slice of solutions sorted from best to worst; i.e. feasible
+// first, then by front and then by ova[0]
+func sortedSolutions(sols []*Solution) (res []*Solution) {
+	res = make([]*Solution, len(sols))
+	copy(res, sols)
+	sort.SliceStable(res, func(i, j int) bool {
+		fi, fj := res[i].Feasible(), res[j].Feasible()
+		if fi != fj {
+			return fi
+		}
+		if res[i].FrontId != res[j].FrontId {
+			return res[i].FrontId < res[j].FrontId
+		}
+		return res[i].Ova[0] < res[j].Ova[0]
+	})
+	return
+}
+
+// shuffledSolutions returns a copy of the slice of solutions in random order
+func shuffledSolutions(sols []*Solution, rng *Rnd) (res []*Solution) {
+	res = make([]*Solution, len(sols))
+	for i, k := range rng.IntGetShuffled(utl.IntRange(len(sols))) {
+		res[i] = sols[k]
+	}
+	return
+}
+
+// snapshot returns a copy of sol including the metrics used by Fight
+func snapshot(sol *Solution) (res *Solution) {
+	res = NewSolution(sol.Id, 0, sol.prms)
+	sol.CopyInto(res)
+	res.FrontId = sol.FrontId
+	res.DistCrowd = sol.DistCrowd
+	res.DistNeigh = sol.DistNeigh
+	return
+}
@@ -0,0 +1,153 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"sort"
+	"time"
+)
+
+// Event holds data about something that has happened during the evolution
+//  Note: the solutions in Event are views to the solutions in the Optimiser; thus, they must not be
+//        modified and must be copied if needed after the callback returns
+type Event struct {
+	Group      int           // group (CPU) id; -1 if the event concerns all groups
+	Time       int           // current time
+	Nfeval     int           // number of function evaluations; of the group if Group ≥ 0
+	Elapsed    time.Duration // time elapsed since the beginning of the evolution
+	Migrated   []*Solution   // ExchangeDone: solutions that have received data from another group
+	Best       *Solution     // NewBest: best feasible solution according to Ova[0]
+	Front0     []*Solution   // Front0Changed: feasible solutions in the first Pareto front
+	StopReason string        // RunFinished: why the evolution has stopped; see Stat.StopReason
+}
+
+// Observer receives events during the evolution
+//  Note: the calls are serialised; i.e. an Observer does not need to be safe for concurrent use.
+//        However, the calls must return quickly because the groups wait for them
+type Observer interface {
+	GenerationDone(e *Event) // one group has finished one generation
+	ExchangeDone(e *Event)   // solutions have been exchanged between groups
+	NewBest(e *Event)        // a better feasible solution has been found
+	Front0Changed(e *Event)  // the feasible solutions in the first Pareto front have changed (Nova > 1)
+	RunFinished(e *Event)    // the evolution has stopped
+}
+
+// NopObserver implements Observer with methods that do nothing. It can be embedded in structures
+// that implement only some of the methods of Observer
+type NopObserver struct{}
+
+func (o NopObserver) GenerationDone(e *Event) {}
+func (o NopObserver) ExchangeDone(e *Event)   {}
+func (o NopObserver) NewBest(e *Event)        {}
+func (o NopObserver) Front0Changed(e *Event)  {}
+func (o NopObserver) RunFinished(e *Event)    {}
+
+// observers ///////////////////////////////////////////////////////////////////////////////////////
+
+// initObservers initialises the data to track changes at the beginning of the evolution
+func (o *Optimiser) initObservers() {
+	o.tstart = time.Now()
+	o.obsBest = INF
+	o.obsFront = nil
+}
+
+// notify calls method for all observers
+func (o *Optimiser) notify(method func(Observer, *Event), e *Event) {
+	o.obsMutex.Lock()
+	defer o.obsMutex.Unlock()
+	e.Elapsed = time.Now().Sub(o.tstart)
+	for _, obs := range o.Observers {
+		method(obs, e)
+	}
+}
+
+// generationDone notifies that one group has finished one generation and checks whether a better
+// feasible solution has been found in this group or not. Called concurrently by the groups
+func (o *Optimiser) generationDone(cpu, time int) {
+	if len(o.Observers) == 0 {
+		return
+	}
+	grp := o.Groups[cpu]
+	o.notify(Observer.GenerationDone, &Event{Group: cpu, Time: time, Nfeval: grp.Nfeval})
+	o.checkNewBest(grp.All[:grp.Ncur], cpu, time, grp.Nfeval)
+}
+
+// exchangeDone notifies that solutions have been exchanged and checks whether the best feasible
+// solution or the first front have changed or not
+func (o *Optimiser) exchangeDone(time int, migrated []*Solution) {
+	if len(o.Observers) == 0 {
+		return
+	}
+	o.notify(Observer.ExchangeDone, &Event{Group: -1, Time: time, Nfeval: o.Nfeval, Migrated: migrated})
+	o.checkNewBest(o.Solutions, -1, time, o.Nfeval)
+	o.checkFront0(time)
+}
+
+// runFinished notifies that the evolution has stopped
+func (o *Optimiser) runFinished() {
+	if len(o.Observers) == 0 {
+		return
+	}
+	o.notify(Observer.RunFinished, &Event{Group: -1, Time: o.tcur, Nfeval: o.Nfeval, StopReason: o.StopReason})
+}
+
+// checkNewBest notifies if a feasible solution better than the best one so far is found in sols
+func (o *Optimiser) checkNewBest(sols []*Solution, cpu, time, nfeval int) {
+	var best *Solution
+	for _, sol := range sols {
+		if sol.Feasible() && (best == nil || sol.Ova[0] < best.Ova[0]) {
+			best = sol
+		}
+	}
+	if best == nil {
+		return
+	}
+	o.obsMutex.Lock()
+	improved := best.Ova[0] < o.obsBest
+	if improved {
+		o.obsBest = best.Ova[0]
+	}
+	o.obsMutex.Unlock()
+	if improved {
+		o.notify(Observer.NewBest, &Event{Group: cpu, Time: time, Nfeval: nfeval, Best: best})
+	}
+}
+
+// checkFront0 notifies if the feasible solutions in the first Pareto front have changed. Must be
+// called after Metrics.Compute(Solutions)
+func (o *Optimiser) checkFront0(time int) {
+	if o.Nova < 2 {
+		return
+	}
+	var front []*Solution
+	var ovas [][]float64
+	for _, sol := range o.Solutions {
+		if sol.Feasible() && sol.FrontId == 0 {
+			front = append(front, sol)
+			ovas = append(ovas, append([]float64{}, sol.Ova...))
+		}
+	}
+	sort.Slice(ovas, func(i, j int) bool {
+		for k := range ovas[i] {
+			if ovas[i][k] != ovas[j][k] {
+				return ovas[i][k] < ovas[j][k]
+			}
+		}
+		return false
+	})
+	changed := len(ovas) != len(o.obsFront)
+	for i := 0; !changed && i < len(ovas); i++ {
+		for k := range ovas[i] {
+			if ovas[i][k] != o.obsFront[i][k] {
+				changed = true
+				break
+			}
+		}
+	}
+	o.obsFront = ovas
+	if changed && len(front) > 0 {
+		o.notify(Observer.Front0Changed, &Event{Group: -1, Time: time, Nfeval: o.Nfeval, Front0: front})
+	}
+}
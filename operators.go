@@ -4,41 +4,225 @@
 
 package goga
 
-import "github.com/cpmech/gosl/rnd"
+import "math"
 
-// DiffEvol performs the differential-evolution operation
+// DiffEvol performs the differential-evolution operation with the rand/1 strategy; i.e. the mutant
+// x0 + F (x1 - x2) is crossed over with x according to DEcross
+//  Note: the random numbers are drawn from the default stream; see InitDefaultRnd
 func DiffEvol(xnew, x, x0, x1, x2 []float64, prms *Parameters) {
+	withDefaultRnd(func(rng *Rnd) { DiffEvolRng(xnew, x, x0, x1, x2, prms, rng) })
+}
+
+// DiffEvolRng is the same as DiffEvol but uses the given stream of random numbers
+func DiffEvolRng(xnew, x, x0, x1, x2 []float64, prms *Parameters, rng *Rnd) {
+	diffEvol(xnew, x, nil, [][]float64{x0, x1, x2}, "rand/1", prms.DEF, prms.DEC, prms, rng)
+}
+
+// DiffEvolStrategy performs the differential-evolution operation with the DEstrategy strategy. The
+// mutants are:
+//   rand/1             -- r0 + F (r1 - r2)
+//   best/1             -- best + F (r1 - r2)
+//   current-to-best/1  -- x + F (best - x) + F (r1 - r2)
+//   rand/2             -- r0 + F (r1 - r2) + F (r3 - r4)
+//   current-to-pbest/1 -- x + F (best - x) + F (r1 - r2)
+//  Input:
+//   x    -- current (target) vector
+//   best -- best vector of the group for best/1 and current-to-best/1; or one of the best vectors
+//           for current-to-pbest/1 (see DEp). may be nil for rand/1 and rand/2
+//   r    -- random vectors of the group. len(r) must be at least 3; or 5 for rand/2
+//  Output:
+//   xnew -- new vector
+func DiffEvolStrategy(xnew, x, best []float64, r [][]float64, prms *Parameters, rng *Rnd) {
+	diffEvol(xnew, x, best, r, prms.DEstrategy, prms.DEF, prms.DEC, prms, rng)
+}
+
+// diffEvol implements DiffEvol and DiffEvolStrategy with scale factor F (random if F ≤ 0) and
+// crossover rate CR
+func diffEvol(xnew, x, best []float64, r [][]float64, strategy string, F, CR float64, prms *Parameters, rng *Rnd) {
 
 	// normalise variables
-	r, r0, r1, r2 := prms.Normalise4(x, x0, x1, x2)
+	xc := prms.Normalise1(x)
+	rc := make([][]float64, len(r))
+	for k := range r {
+		rc[k] = prms.Normalise1(r[k])
+	}
+	var bc []float64
+	if best != nil {
+		bc = prms.Normalise1(best)
+	}
 
-	// perform DE
+	// mutant
 	n := len(xnew)
-	F := rnd.Float64(0.0, 1.0)
-	I := rnd.Int(0, n-1)
-	for i := 0; i < n; i++ {
-		if rnd.FlipCoin(prms.DEC) || i == I {
-			xnew[i] = r0[i] + F*(r1[i]-r2[i])
-			if prms.NormFlt {
-				if xnew[i] < 0 {
-					xnew[i] = 0
-				}
-				if xnew[i] > 1 {
-					xnew[i] = 1
-				}
+	if F <= 0 {
+		F = rng.Float64(0.0, 1.0)
+	}
+	mutant := func(i int) (v float64) {
+		switch strategy {
+		case "best/1":
+			v = bc[i] + F*(rc[1][i]-rc[2][i])
+		case "current-to-best/1", "current-to-pbest/1":
+			v = xc[i] + F*(bc[i]-xc[i]) + F*(rc[1][i]-rc[2][i])
+		case "rand/2":
+			v = rc[0][i] + F*(rc[1][i]-rc[2][i]) + F*(rc[3][i]-rc[4][i])
+		default: // rand/1
+			v = rc[0][i] + F*(rc[1][i]-rc[2][i])
+		}
+		return boundFlt(v, xc[i], i, prms, rng)
+	}
+
+	// crossover
+	I := rng.Int(0, n-1)
+	if prms.DEcross == "exp" {
+		copy(xnew, xc)
+		for l := 0; l < n; l++ {
+			i := (I + l) % n
+			xnew[i] = mutant(i)
+			if !rng.FlipCoin(CR) {
+				break
+			}
+		}
+	} else {
+		for i := 0; i < n; i++ {
+			if rng.FlipCoin(CR) || i == I {
+				xnew[i] = mutant(i)
 			} else {
-				if xnew[i] < prms.FltMin[i] {
-					xnew[i] = prms.FltMin[i]
-				}
-				if xnew[i] > prms.FltMax[i] {
-					xnew[i] = prms.FltMax[i]
-				}
+				xnew[i] = xc[i]
 			}
-		} else {
-			xnew[i] = r[i]
 		}
 	}
 
 	// de-normalise result
 	prms.DeNormalise1(xnew)
 }
+
+// CxFltSBX performs the simulated binary crossover (SBX) of A and B with probability FltPc and
+// distribution index FltEtac. Each float is crossed over with probability 0.5. The offspring are
+// within FltMin and FltMax (bounded SBX as in NSGA-II); floats with FltMin == FltMax are not changed
+func CxFltSBX(a, b, A, B []float64, prms *Parameters, rng *Rnd) {
+	copy(a, A)
+	copy(b, B)
+	if !rng.FlipCoin(prms.FltPc) {
+		return
+	}
+	ra, rb := prms.Normalise1(A), prms.Normalise1(B)
+	η := prms.FltEtac
+	for i := 0; i < len(a); i++ {
+		a[i], b[i] = ra[i], rb[i]
+		lo, hi := fltRange(i, prms)
+		if hi <= lo || !rng.FlipCoin(0.5) || math.Abs(ra[i]-rb[i]) < 1e-14 {
+			continue // zero range: the float is pinned
+		}
+		y1, y2 := math.Min(ra[i], rb[i]), math.Max(ra[i], rb[i])
+		u := rng.Float64(0, 1)
+		βq := func(β float64) float64 {
+			α := 2.0 - math.Pow(β, -(η+1.0))
+			if u <= 1.0/α {
+				return math.Pow(u*α, 1.0/(η+1.0))
+			}
+			return math.Pow(1.0/(2.0-u*α), 1.0/(η+1.0))
+		}
+		c1 := 0.5 * ((y1 + y2) - βq(1.0+2.0*(y1-lo)/(y2-y1))*(y2-y1))
+		c2 := 0.5 * ((y1 + y2) + βq(1.0+2.0*(hi-y2)/(y2-y1))*(y2-y1))
+		c1, c2 = boundFlt(c1, y1, i, prms, rng), boundFlt(c2, y2, i, prms, rng)
+		if rng.FlipCoin(0.5) {
+			c1, c2 = c2, c1
+		}
+		a[i], b[i] = c1, c2
+	}
+	prms.DeNormalise1(a)
+	prms.DeNormalise1(b)
+}
+
+// MtFltPoly performs the polynomial mutation of each float of a with probability FltPm (1/Nflt if
+// zero) and distribution index FltEtam. The result is within FltMin and FltMax; floats with
+// FltMin == FltMax are not changed
+func MtFltPoly(a []float64, prms *Parameters, rng *Rnd) {
+	pm := prms.FltPm
+	if pm == 0 {
+		pm = 1.0 / float64(len(a))
+	}
+	r := prms.Normalise1(a)
+	η := prms.FltEtam
+	for i := 0; i < len(a); i++ {
+		lo, hi := fltRange(i, prms)
+		if hi <= lo || !rng.FlipCoin(pm) {
+			continue // zero range: the float is pinned
+		}
+		δ1, δ2 := (r[i]-lo)/(hi-lo), (hi-r[i])/(hi-lo)
+		u := rng.Float64(0, 1)
+		var δq float64
+		if u <= 0.5 {
+			v := 2.0*u + (1.0-2.0*u)*math.Pow(1.0-δ1, η+1.0)
+			δq = math.Pow(v, 1.0/(η+1.0)) - 1.0
+		} else {
+			v := 2.0*(1.0-u) + 2.0*(u-0.5)*math.Pow(1.0-δ2, η+1.0)
+			δq = 1.0 - math.Pow(v, 1.0/(η+1.0))
+		}
+		r[i] = boundFlt(r[i]+δq*(hi-lo), r[i], i, prms, rng)
+	}
+	prms.DeNormalise1(r)
+	copy(a, r)
+}
+
+// fltRange returns the range of the (normalised) i-th float
+func fltRange(i int, prms *Parameters) (lo, hi float64) {
+	if prms.NormFlt {
+		return 0, 1
+	}
+	return prms.FltMin[i], prms.FltMax[i]
+}
+
+// boundFlt handles the (normalised) i-th float v of an offspring of parent according to FltBound.
+// With "resample", v is returned unchanged because the caller re-applies the operator
+func boundFlt(v, parent float64, i int, prms *Parameters, rng *Rnd) float64 {
+	if prms.FltBound == "resample" {
+		return v
+	}
+	lo, hi := fltRange(i, prms)
+	return applyBound(v, parent, lo, hi, prms.FltBound, rng)
+}
+
+// applyBound brings v back into [lo,hi] according to rule:
+//   clamp    -- v is set to the violated bound
+//   reflect  -- v is reflected back from the violated bound
+//   wrap     -- v re-enters the range from the opposite bound (periodic)
+//   random   -- v is set to a random value in [lo,hi]
+//   midpoint -- v is set to the midpoint between the violated bound and parent
+//  Note: other rules and "random" with rng == nil are treated as clamp
+func applyBound(v, parent, lo, hi float64, rule string, rng *Rnd) float64 {
+	if v >= lo && v <= hi {
+		return v
+	}
+	d := hi - lo
+	switch {
+	case d <= 0:
+		return lo
+	case rule == "reflect":
+		t := math.Mod(v-lo, 2.0*d)
+		if t < 0 {
+			t += 2.0 * d
+		}
+		if t > d {
+			t = 2.0*d - t
+		}
+		return lo + t
+	case rule == "wrap":
+		t := math.Mod(v-lo, d)
+		if t < 0 {
+			t += d
+		}
+		return lo + t
+	case rule == "random" && rng != nil:
+		return rng.Float64(lo, hi)
+	case rule == "midpoint":
+		parent = math.Min(math.Max(parent, lo), hi)
+		if v < lo {
+			return (lo + parent) / 2.0
+		}
+		return (hi + parent) / 2.0
+	}
+	if v < lo {
+		return lo
+	}
+	return hi
+}
@@ -5,21 +5,16 @@
 package goga
 
 import (
-	"math"
+	"context"
+	"sort"
+	"sync"
 	gotime "time"
 
 	"github.com/cpmech/gosl/chk"
-	"github.com/cpmech/gosl/gm/tri"
 	"github.com/cpmech/gosl/io"
-	"github.com/cpmech/gosl/rnd"
 	"github.com/cpmech/gosl/utl"
 )
 
-type Mesh struct {
-	V [][]float64 // vertices
-	C [][]int     // cells
-}
-
 // Optimiser solves optimisation problems:
 //  Solve:
 //   min  {Ova[0](x), Ova[1](x), ...} objective values
@@ -40,89 +35,247 @@ type Mesh struct {
 type Optimiser struct {
 
 	// input
-	Parameters           // input parameters
-	ObjFunc    ObjFunc_t // [optional] objective function
-	MinProb    MinProb_t // [optional] minimisation problem function
-	CxInt      CxInt_t   // [optional] crossover function for ints
-	MtInt      MtInt_t   // [optional] mutation function for ints
-	Output     Output_t  // [optional] output function
+	Parameters                  // input parameters
+	ObjFunc      ObjFunc_t      // [optional] objective function
+	ObjFuncErr   ObjFuncErr_t   // [optional] objective function that may fail
+	BatchObjFunc BatchObjFunc_t // [optional] objective function for all offspring of a group at once
+	MinProb      MinProb_t      // [optional] minimisation problem function
+	MinProbErr   MinProbErr_t   // [optional] minimisation problem function that may fail
+	CxInt        CxInt_t        // [optional] crossover function for ints
+	MtInt        MtInt_t        // [optional] mutation function for ints
+	CxIntRng     CxIntRng_t     // [optional] crossover function for ints using the streams of the groups; instead of CxInt
+	MtIntRng     MtIntRng_t     // [optional] mutation function for ints using the streams of the groups; instead of MtInt
+	GeneratorRng GeneratorRng_t // [optional] generator using the streams of the optimiser; instead of the one given to Init
+	CxFlt        CxFlt_t        // [optional] crossover function for floats; default: see FltOp
+	MtFlt        MtFlt_t        // [optional] mutation function for floats; applied after CxFlt or DiffEvol
+	Output       Output_t       // [optional] output function
+
+	// termination and observers
+	Terminators []Terminator // [optional] extra termination criteria; Solve stops when any is done
+	Observers   []Observer   // [optional] receive events during the evolution
+
+	// islands
+	Islands   []*Island // [optional] [ncpu] parameters of each group (island)
+	Migration Migration // [optional] exchange of solutions between groups; e.g. Migrator. default: ExcTour and ExcOne
+
+	// seeds
+	SeedFlt [][]float64 // [optional] [nseeds][nflt] floats placed into the initial solutions; e.g. from ReadSeeds
+	SeedInt [][]int     // [optional] [nseeds][nint] ints placed into the initial solutions; e.g. from ReadSeeds
 
 	// essential
-	Generator Generator_t // generate solutions
+	Generator Generator_t // generate solutions; may be nil
 	Solutions []*Solution // current solutions
 	Groups    []*Group    // [cpu] competitors per CPU. pointers to current and future solutions
 	Metrics   *Metrics    // metrics
+	Rnd       *Rnd        // random numbers stream for exchanges and generation of all solutions
+	Cache     *Cache      // cache of objective values; allocated if CacheSize > 0
+	Best      *Solution   // best feasible solution found in this run, including previous restarts (only if RestartNexc > 0)
+	Archive   *Archive    // feasible non-dominated solutions found in this run; allocated if ArchSize > 0
 
 	// meshes
 	Meshes [][]*Mesh // meshes for (xi,xj) points. [nflt-1][nflt] only upper diagonal entries
 
 	// auxiliary
-	Stat                   // structure holding stat data
-	Nf, Ng, Nh int         // number of f, g, h functions
-	F, G, H    [][]float64 // [cpu] temporary
-	tmp        *Solution   // temporary solution
-	cpupairs   [][]int     // pairs of CPU ids. for exchanging solutions
-	iova0      int         // index of current item in ova[0]
-	ova0       []float64   // last ova[0] values to assess convergence
+	Stat                         // structure holding stat data
+	Nf, Ng, Nh int               // number of f, g, h functions
+	F, G, H    [][]float64       // [cpu] temporary
+	tmp        *Solution         // temporary solution
+	cpupairs   [][]int           // pairs of CPU ids. for exchanging solutions
+	iova0      int               // index of current item in ova[0]
+	ova0       []float64         // last ova[0] values to assess convergence
+	tcur       int               // current time
+	resume     bool              // Solve must resume from tcur; e.g. after LoadCheckpoint
+	askIni     bool              // Ask must return the initial solutions; i.e. they have not been evaluated yet
+	askDone    bool              // Ask must not return more solutions; i.e. Tell has reported that the run is done
+	asked      []*Solution       // solutions returned by Ask and waiting for Tell
+	tstart     gotime.Time       // time when the evolution started
+	obsMutex   sync.Mutex        // serialises calls to Observers
+	obsBest    float64           // best feasible ova[0] notified to Observers
+	obsFront   [][]float64       // ovas of feasible solutions in front 0 notified to Observers
+	solIndex   map[*Solution]int // maps solutions to their indices in Solutions; e.g. vertices in Meshes
+	nsol0      int               // initial number of solutions; i.e. before restarts with growth
+	workers    chan func(int)    // jobs for the workers evaluating objective functions (if Nworkers > 0)
+	cpuOffset  int               // added to the cpu index passed to the objective functions; e.g. by RunMany
+	generator  GeneratorRng_t    // GeneratorRng, Generator (or its version with stream) or GenTrialSolutionsRng
 }
 
 // Initialises continues initialisation by generating individuals
 //  Optional:  obj  XOR  fcn, nf, ng, nh
+//  Note: obj and fcn may be both nil if BatchObjFunc or ObjFuncErr has been set; or if MinProbErr
+//        has been set, in which case nf, ng and nh must be given
+//  Note: gen may be nil, in which case GeneratorRng or GenTrialSolutionsRng is used with the streams
+//        of the optimiser. GenTrialSolutions is replaced by GenTrialSolutionsRng; thus, Seed holds.
+//        Other generators draw random numbers from their own source
 func (o *Optimiser) Init(gen Generator_t, obj ObjFunc_t, fcn MinProb_t, nf, ng, nh int) {
+	err := o.InitErr(gen, obj, fcn, nf, ng, nh)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}
+
+// InitErr is the same as Init but returns an error instead of panicking. The parameters are
+// checked with Validate; thus, err may be of type ParamErrors
+func (o *Optimiser) InitErr(gen Generator_t, obj ObjFunc_t, fcn MinProb_t, nf, ng, nh int) (err error) {
 
 	// generic or minimisation problem
+	err = o.setObjective(obj, fcn, nf, ng, nh)
+	if err != nil {
+		return
+	}
+
+	// allocate and generate solutions
+	return o.initialise(gen)
+}
+
+// setObjective sets the objective function. MinProb and MinProbErr are wrapped by ObjFunc and
+// ObjFuncErr, respectively
+func (o *Optimiser) setObjective(obj ObjFunc_t, fcn MinProb_t, nf, ng, nh int) (err error) {
 	if obj != nil {
 		o.ObjFunc = obj
+	} else if fcn == nil && o.MinProbErr == nil {
+		if o.BatchObjFunc == nil && o.ObjFuncErr == nil {
+			return chk.Err("either ObjFunc, ObjFuncErr, MinProb, MinProbErr or BatchObjFunc must be provided")
+		}
 	} else {
-		if fcn == nil {
-			chk.Panic("either ObjFunc or MinProb must be provided")
-		}
-		o.Nf, o.Ng, o.Nh, o.MinProb = nf, ng, nh, fcn
-		o.ObjFunc = func(sol *Solution, cpu int) {
-			o.MinProb(o.F[cpu], o.G[cpu], o.H[cpu], sol.Flt, sol.Int, cpu)
-			for i, f := range o.F[cpu] {
-				sol.Ova[i] = f
-			}
-			for i, g := range o.G[cpu] {
-				sol.Oor[i] = utl.GtePenalty(g, 0.0, 1) // g[i] ≥ 0
+		o.Nf, o.Ng, o.Nh = nf, ng, nh
+		if fcn != nil {
+			o.MinProb = fcn
+			o.ObjFunc = func(sol *Solution, cpu int) {
+				k := cpu - o.cpuOffset // index of buffers
+				o.MinProb(o.F[k], o.G[k], o.H[k], sol.Flt, sol.Int, cpu)
+				o.setOvaOor(sol, k)
 			}
-			for i, h := range o.H[cpu] {
-				h = math.Abs(h)
-				sol.Ova[0] += h
-				sol.Oor[o.Ng+i] = utl.GtePenalty(o.EpsH, h, 1) // ϵ ≥ |h[i]|
+		} else {
+			o.ObjFuncErr = func(sol *Solution, cpu int) (err error) {
+				k := cpu - o.cpuOffset // index of buffers
+				err = o.MinProbErr(o.F[k], o.G[k], o.H[k], sol.Flt, sol.Int, cpu)
+				if err == nil {
+					o.setOvaOor(sol, k)
+				}
+				return
 			}
 		}
-		o.F = utl.Alloc(o.Ncpu, o.Nf)
-		o.G = utl.Alloc(o.Ncpu, o.Ng)
-		o.H = utl.Alloc(o.Ncpu, o.Nh)
+		ncpu := utl.Imax(o.Ncpu, o.Nworkers)
+		o.F = utl.Alloc(ncpu, o.Nf)
+		o.G = utl.Alloc(ncpu, o.Ng)
+		o.H = utl.Alloc(ncpu, o.Nh)
 		o.Nova = o.Nf
 		o.Noor = o.Ng + o.Nh
 	}
+	return
+}
+
+// initialise calculates derived parameters, allocates solutions and generates trial solutions
+func (o *Optimiser) initialise(gen Generator_t) (err error) {
 
 	// calc derived parameters
+	err = o.Validate()
+	if err != nil {
+		return
+	}
 	o.Generator = gen
+	o.generator = o.GeneratorRng
+	if o.generator == nil {
+		o.generator = builtinGenerator(gen)
+	}
+	if o.generator == nil {
+		o.generator = GenTrialSolutionsRng
+		if gen != nil {
+			o.generator = func(sols []*Solution, prms *Parameters, reset bool, rng *Rnd) {
+				gen(sols, prms, reset)
+			}
+		}
+	}
 	o.CalcDerived()
+	o.nsol0 = o.Nsol
+	err = o.checkIslands()
+	if err != nil {
+		return
+	}
+	for _, t := range o.Terminators {
+		err = validateTerminator(t)
+		if err != nil {
+			return
+		}
+	}
+	if o.Migration != nil {
+		err = o.Migration.Init(o)
+		if err != nil {
+			return
+		}
+	}
 
-	// allocate solutions
-	o.Solutions = NewSolutions(o.Nsol, &o.Parameters)
-	o.Groups = make([]*Group, o.Ncpu)
-	for cpu := 0; cpu < o.Ncpu; cpu++ {
-		o.Groups[cpu] = new(Group)
-		o.Groups[cpu].Init(cpu, o.Ncpu, o.Solutions, &o.Parameters)
+	// allocate data structures and generate trial solutions
+	o.prepare()
+	o.resetCounters()
+	return o.generate_solutions(false)
+}
+
+// prepare allocates solutions, groups, metrics, random numbers streams, cache, archive and
+// auxiliary data using the derived parameters
+func (o *Optimiser) prepare() {
+
+	// solutions, groups and metrics
+	o.allocate(o.nsol0)
+
+	// random numbers
+	o.Rnd = new(Rnd)
+	o.initRnd()
+
+	// cache
+	o.Cache = nil
+	if o.CacheSize > 0 {
+		o.Cache = NewCache(o.CacheSize, o.CacheTol)
 	}
 
-	// metrics
-	o.Metrics = new(Metrics)
-	o.Metrics.Init(o.Nsol, &o.Parameters)
+	// archive
+	o.Archive = nil
+	if o.ArchSize > 0 {
+		o.Archive = NewArchive(o.ArchSize, o.ArchPrune, &o.Parameters)
+	}
 
 	// auxiliary
 	o.tmp = NewSolution(0, 0, &o.Parameters)
 	o.cpupairs = utl.IntAlloc(o.Ncpu/2, 2)
 	o.iova0 = -1
 	o.ova0 = make([]float64, o.Tmax)
+}
 
-	// generate trial solutions
-	o.generate_solutions(false)
+// allocate allocates nsol solutions, the groups and the metrics
+func (o *Optimiser) allocate(nsol int) {
+	o.Nsol = nsol
+	o.Solutions = NewSolutions(o.Nsol, &o.Parameters)
+	o.solIndex = make(map[*Solution]int)
+	for i, sol := range o.Solutions {
+		o.solIndex[sol] = i
+	}
+	o.Groups = make([]*Group, o.Ncpu)
+	for cpu := 0; cpu < o.Ncpu; cpu++ {
+		start, endp1 := o.groupRange(cpu)
+		o.Groups[cpu] = new(Group)
+		o.Groups[cpu].initRange(start, endp1, o.Solutions, o.groupParams(cpu))
+	}
+	o.Metrics = new(Metrics)
+	o.Metrics.Init(o.Nsol, &o.Parameters)
+}
+
+// SetFixed sets the values of solution i, evaluates it and marks it as Fixed; thus, it is never
+// replaced during the evolution, including after Reset
+//  Note: with Ask and Tell, the solution is evaluated externally after the next call to Ask
+func (o *Optimiser) SetFixed(i int, flt []float64, ints []int) {
+	sol := o.Solutions[i]
+	copy(sol.Flt, flt)
+	copy(sol.Int, ints)
+	sol.Fixed = true
+	if o.askIni {
+		return
+	}
+	o.Nfeval += o.evaluate([]*Solution{sol}, 0)
+	err := o.collectFailures()
+	if err != nil {
+		chk.Panic("cannot evaluate fixed solution:\n%v", err)
+	}
+	o.Metrics.Compute(o.Solutions)
 }
 
 // GetSolutionsCopy returns a copy of Solutions
@@ -134,37 +287,138 @@ func (o *Optimiser) GetSolutionsCopy() (res []*Solution) {
 	return
 }
 
+// clone returns a new optimiser with the same parameters, functions and Fixed solutions. The
+// objective functions are shared; but Output, Observers and Cache are not. Terminators are copied
+// with cloneTerminator. The solutions of the clone must be generated with Reset
+func (o *Optimiser) clone() (c *Optimiser) {
+
+	// input
+	c = new(Optimiser)
+	c.Parameters = o.Parameters
+	c.ObjFunc, c.ObjFuncErr, c.BatchObjFunc = o.ObjFunc, o.ObjFuncErr, o.BatchObjFunc
+	c.MinProb, c.MinProbErr = o.MinProb, o.MinProbErr
+	c.CxInt, c.MtInt = o.CxInt, o.MtInt
+	c.CxIntRng, c.MtIntRng = o.CxIntRng, o.MtIntRng
+	c.GeneratorRng = o.GeneratorRng
+	c.CxFlt, c.MtFlt = o.CxFlt, o.MtFlt
+	c.Islands = o.Islands
+	c.Migration = o.Migration
+	c.SeedFlt, c.SeedInt = o.SeedFlt, o.SeedInt
+	for _, t := range o.Terminators {
+		c.Terminators = append(c.Terminators, cloneTerminator(t))
+	}
+	c.Stat = o.Stat // formatting and reference data
+
+	// the wrappers of MinProb and MinProbErr must use the buffers of the clone
+	if c.MinProb != nil || c.MinProbErr != nil {
+		c.setObjective(nil, c.MinProb, o.Nf, o.Ng, o.Nh)
+	}
+
+	// allocate data structures
+	c.Generator, c.generator = o.Generator, o.generator
+	c.nsol0 = o.nsol0
+	c.prepare()
+	for i, sol := range o.Solutions {
+		if sol.Fixed && i < c.Nsol {
+			sol.CopyInto(c.Solutions[i])
+			c.Solutions[i].Fixed = true
+		}
+	}
+	return
+}
+
 // Reset resets all variables for a next sample run
+//  Note: the initial number of solutions is recovered if it has grown due to restarts
 func (o *Optimiser) Reset(reSeed bool) {
+	if o.Nsol != o.nsol0 {
+		o.resize(o.nsol0)
+	}
 	if reSeed {
-		rnd.Init(o.Seed)
+		o.initRnd()
+	}
+	o.Restarts, o.Best = nil, nil
+	if o.Archive != nil {
+		o.Archive.Clear()
+	}
+	o.resetCounters()
+	err := o.generate_solutions(true)
+	if err != nil {
+		chk.Panic("%v", err)
 	}
-	o.generate_solutions(true)
 	for cpu := 0; cpu < o.Ncpu; cpu++ {
-		o.Groups[cpu].Reset(cpu, o.Ncpu, o.Solutions)
+		start, _ := o.groupRange(cpu)
+		o.Groups[cpu].resetRange(start, o.Solutions)
 	}
+	o.tcur = 0
+	o.resume = false
+	o.asked = nil
 }
 
 // Solve solves optimisation problem
+//  Note: Solve panics if an evaluation fails and FailPolicy == "abort"
 func (o *Optimiser) Solve() {
+	err := o.SolveContext(context.Background())
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}
+
+// SolveContext solves optimisation problem until Tmax is reached or ctx is done
+//  Note: ctx is checked by each group before every generation; thus, when ctx is done, all groups
+//        stop at a safe point, the metrics of all solutions are computed and no exchange is
+//        performed. Solutions and Metrics are therefore consistent when this function returns.
+//        The Terminators are checked at exchange times. The reason for stopping is recorded in
+//        StopReason.
+//  Output:
+//   err -- nil if Tmax has been reached; otherwise ctx.Err(); e.g. context.Canceled or
+//          context.DeadlineExceeded; or the error of a failed evaluation if FailPolicy == "abort"
+func (o *Optimiser) SolveContext(ctx context.Context) (err error) {
 
 	// benchmark
 	if o.Verbose {
 		t0 := gotime.Now()
 		defer func() {
+			if err != nil {
+				io.Pfred("\nstopped: %v\n", err)
+			}
 			io.Pf("\nnfeval = %d\n", o.Nfeval)
 			io.Pfblue2("cpu time = %v\n", gotime.Now().Sub(t0))
 		}()
 	}
 
-	// output
-	if o.Output != nil {
-		o.Output(0, o.Solutions)
+	// check
+	if o.askIni {
+		chk.Panic("Solve requires an objective function. use Ask and Tell otherwise")
+	}
+
+	// workers evaluating objective functions
+	stop := o.startWorkers()
+	defer stop()
+
+	// initial time
+	time := 0
+	if o.resume {
+		time = o.tcur
+		o.resume = false
+	}
+	o.tcur = time
+
+	// output and restarts
+	if time == 0 {
+		o.Restarts, o.Best, o.History = nil, nil, nil
+		if o.Output != nil {
+			o.Output(0, o.Solutions)
+		}
 	}
 
+	// termination criteria and observers
+	o.initTerminators()
+	o.initObservers()
+	defer o.runFinished()
+	defer o.restoreBest()
+
 	// perform evolution
 	done := make(chan int, o.Ncpu)
-	time := 0
 	texc := time + o.DtExc
 	for time < o.Tmax {
 
@@ -173,10 +427,14 @@ func (o *Optimiser) Solve() {
 			go func(cpu int) {
 				nfeval := 0
 				for t := time; t < texc; t++ {
+					if ctx.Err() != nil || o.Groups[cpu].err != nil {
+						break
+					}
 					if cpu == 0 && o.Verbose {
 						io.Pf("time = %10d\r", t+1)
 					}
 					nfeval += o.EvolveOneGroup(cpu)
+					o.generationDone(cpu, t+1)
 				}
 				done <- nfeval
 			}(icpu)
@@ -184,66 +442,148 @@ func (o *Optimiser) Solve() {
 		for cpu := 0; cpu < o.Ncpu; cpu++ {
 			o.Nfeval += <-done
 		}
+		if o.Cache != nil {
+			o.Nhits = o.Cache.Hits()
+		}
 
 		// compute metrics with all solutions included
 		o.Metrics.Compute(o.Solutions)
 
-		// exchange via tournament
-		if o.Ncpu > 1 {
-			if o.ExcTour {
-				for i := 0; i < o.Ncpu; i++ {
-					j := (i + 1) % o.Ncpu
-					I := rnd.IntGetUnique(o.Groups[i].Indices, 2)
-					J := rnd.IntGetUnique(o.Groups[j].Indices, 2)
-					A, B := o.Groups[i].All[I[0]], o.Groups[i].All[I[1]]
-					a, b := o.Groups[j].All[J[0]], o.Groups[j].All[J[1]]
-					o.Tournament(A, B, a, b, o.Metrics)
-				}
-			}
+		// stop if an evaluation has failed and FailPolicy == "abort"
+		if err = o.collectFailures(); err != nil {
+			o.StopReason = "failure"
+			return
+		}
 
-			// exchange one randomly
-			if o.ExcOne {
-				rnd.IntGetGroups(o.cpupairs, utl.IntRange(o.Ncpu))
-				for _, pair := range o.cpupairs {
-					i, j := pair[0], pair[1]
-					n := utl.Imin(o.Groups[i].Ncur, o.Groups[j].Ncur)
-					k := rnd.Int(0, n)
-					A := o.Groups[i].All[k]
-					B := o.Groups[j].All[k]
-					B.CopyInto(o.tmp)
-					A.CopyInto(B)
-					o.tmp.CopyInto(A)
-				}
-			}
+		// stop if cancelled or deadline exceeded
+		if err = ctx.Err(); err != nil {
+			o.StopReason = err.Error()
+			return
 		}
 
+		// record best feasible ova[0] and archive best solution
+		o.recordOva0()
+		o.recordHistory(utl.Imin(time+o.DtExc, o.Tmax))
+		o.archiveBest()
+
+		// exchange solutions between groups and update meshes
+		migrated := o.exchange()
+		o.buildMeshes()
+
 		// update time variables
 		time += o.DtExc
 		texc += o.DtExc
 		time = utl.Imin(time, o.Tmax)
 		texc = utl.Imin(texc, o.Tmax)
+		o.tcur = time
 
 		// output
 		if o.Output != nil {
 			o.Output(time, o.Solutions)
 		}
+		o.exchangeDone(time, migrated)
+
+		// check termination criteria
+		if o.terminated() {
+			return
+		}
+
+		// restart if the best feasible ova[0] has stagnated
+		if time < o.Tmax && o.needRestart() {
+			if err = o.restart(time); err != nil {
+				o.StopReason = "failure"
+				return
+			}
+		}
 	}
+	o.StopReason = "tmax"
+	return
 }
 
 // EvolveOneGroup evolves one group (CPU)
+//  Output:
+//   nfeval -- number of function evaluations; i.e. not counting the ones found in Cache
 func (o *Optimiser) EvolveOneGroup(cpu int) (nfeval int) {
+	offspring := o.genOffspring(cpu)
+	nfeval = o.evaluate(offspring, cpu)
+	o.selectSurvivors(cpu)
+	o.Groups[cpu].Nfeval += nfeval
+	return
+}
+
+// Tournament performs the tournament among 4 individuals; ties are broken with the stream Rnd
+func (o *Optimiser) Tournament(A, B, a, b *Solution, m *Metrics) {
+	o.tournament(A, B, a, b, m, o.Rnd)
+}
+
+// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+
+// tournament performs the tournament among 4 individuals and tells whether A and B have been
+// replaced or not. Protected solutions (Fixed or Elite) are never replaced
+func (o *Optimiser) tournament(A, B, a, b *Solution, m *Metrics, rng *Rnd) (replacedA, replacedB bool) {
+	dAa := A.Distance(a, m.Fmin, m.Fmax, m.Imin, m.Imax)
+	dAb := A.Distance(b, m.Fmin, m.Fmax, m.Imin, m.Imax)
+	dBa := B.Distance(a, m.Fmin, m.Fmax, m.Imin, m.Imax)
+	dBb := B.Distance(b, m.Fmin, m.Fmax, m.Imin, m.Imax)
+	if dAa+dBb < dAb+dBa {
+		if !A.Protected() && !A.FightRng(a, rng) {
+			a.CopyInto(A)
+			replacedA = true
+		}
+		if !B.Protected() && !B.FightRng(b, rng) {
+			b.CopyInto(B)
+			replacedB = true
+		}
+		return
+	}
+	if !A.Protected() && !A.FightRng(b, rng) {
+		b.CopyInto(A)
+		replacedA = true
+	}
+	if !B.Protected() && !B.FightRng(a, rng) {
+		a.CopyInto(B)
+		replacedB = true
+	}
+	return
+}
+
+// genOffspring creates the offspring (future solutions) of one group without evaluating them
+func (o *Optimiser) genOffspring(cpu int) (offspring []*Solution) {
 
 	// auxiliary
 	G := o.Groups[cpu].All // competitors (old and new)
 	I := o.Groups[cpu].Indices
 	P := o.Groups[cpu].Pairs
+	rng := o.Groups[cpu].Rnd
+	prms := o.Groups[cpu].Prms
+	cxInt, mtInt := o.groupOperators(cpu)
+	cxFlt, mtFlt := o.groupFltOperators(cpu, prms)
 
 	// compute random pairs
-	rnd.IntGetGroups(P, I)
+	rng.IntGetGroups(P, I)
 	np := len(P)
 
-	// create new solutions
+	// rank current solutions (by front and ova[0]) if differential evolution requires the best ones
 	z := o.Groups[cpu].Ncur // index of first new solution
+	var ranked []*Solution
+	if o.Nflt > 0 && cxFlt == nil && prms.DEstrategy != "rand/1" && prms.DEstrategy != "rand/2" {
+		o.Groups[cpu].Metrics.Compute(G[:z])
+		ranked = append([]*Solution{}, G[:z]...)
+		sortByFrontThenOva(ranked, 0)
+	}
+	best := func() []float64 {
+		if ranked == nil {
+			return nil
+		}
+		if prms.DEstrategy == "current-to-pbest/1" {
+			nbest := utl.Imax(1, int(prms.DEp*float64(len(ranked))+0.5))
+			return ranked[rng.Int(0, nbest-1)].Flt
+		}
+		return ranked[0].Flt
+	}
+
+	// create new solutions
+	offspring = o.Groups[cpu].Offspring[:0]
 	for k := 0; k < np; k++ {
 		l := (k + 1) % np
 		m := (k + 2) % np
@@ -263,14 +603,41 @@ func (o *Optimiser) EvolveOneGroup(cpu int) (nfeval int) {
 		b := G[z+P[k][1]]
 
 		if o.Nflt > 0 {
-			DiffEvol(a.Flt, A.Flt, A0.Flt, A1.Flt, A2.Flt, &o.Parameters)
-			DiffEvol(b.Flt, B.Flt, B0.Flt, B1.Flt, B2.Flt, &o.Parameters)
+			for try := 1; ; try++ {
+				if cxFlt == nil {
+					A3, A4 := G[P[(k+4)%np][0]], G[P[(k+5)%np][0]]
+					B3, B4 := G[P[(k+4)%np][1]], G[P[(k+5)%np][1]]
+					Fa, CRa := o.deControl(cpu, a, A)
+					Fb, CRb := o.deControl(cpu, b, B)
+					diffEvol(a.Flt, A.Flt, best(), [][]float64{A0.Flt, A1.Flt, A2.Flt, A3.Flt, A4.Flt}, prms.DEstrategy, Fa, CRa, prms, rng)
+					diffEvol(b.Flt, B.Flt, best(), [][]float64{B0.Flt, B1.Flt, B2.Flt, B3.Flt, B4.Flt}, prms.DEstrategy, Fb, CRb, prms, rng)
+				} else {
+					cxFlt(a.Flt, b.Flt, A.Flt, B.Flt, prms, rng)
+				}
+				if mtFlt != nil {
+					mtFlt(a.Flt, prms, rng)
+					mtFlt(b.Flt, prms, rng)
+				}
+				if prms.FltBound != "resample" || try == NRESAMPLE || (prms.fltInRange(a.Flt) && prms.fltInRange(b.Flt)) {
+					break
+				}
+			}
+			// the built-in operators have applied FltBound already, but the user-defined ones may
+			// not have done so. clamping only corrects round-off errors
+			for i := 0; i < o.Nflt; i++ {
+				a.Flt[i] = prms.clampFlt(i, prms.BoundFlt(i, a.Flt[i], A.Flt[i], rng))
+				b.Flt[i] = prms.clampFlt(i, prms.BoundFlt(i, b.Flt[i], B.Flt[i], rng))
+			}
+			if o.UseMesh {
+				o.meshMove(a.Flt, A, rng)
+				o.meshMove(b.Flt, B, rng)
+			}
 		}
 
 		if o.Nint > 0 {
-			o.CxInt(a.Int, b.Int, A.Int, B.Int, &o.Parameters)
-			o.MtInt(a.Int, &o.Parameters)
-			o.MtInt(b.Int, &o.Parameters)
+			cxInt(a.Int, b.Int, A.Int, B.Int, prms, rng)
+			mtInt(a.Int, prms, rng)
+			mtInt(b.Int, prms, rng)
 		}
 
 		if o.BinInt > 0 && o.ClearFlt {
@@ -284,52 +651,218 @@ func (o *Optimiser) EvolveOneGroup(cpu int) (nfeval int) {
 			}
 		}
 
-		o.ObjFunc(a, cpu)
-		o.ObjFunc(b, cpu)
-		nfeval += 2
+		offspring = append(offspring, a, b)
 	}
+	o.Groups[cpu].Offspring = offspring
+	return
+}
+
+// selectSurvivors performs the tournaments between current solutions and (evaluated) offspring
+func (o *Optimiser) selectSurvivors(cpu int) {
+
+	// auxiliary
+	G := o.Groups[cpu].All
+	P := o.Groups[cpu].Pairs
+	rng := o.Groups[cpu].Rnd
+	z := o.Groups[cpu].Ncur
 
 	// metrics
 	o.Groups[cpu].Metrics.Compute(G)
 
+	// elite solutions
+	if o.Nelite > 0 {
+		o.markElite(G[:z])
+	}
+
 	// tournaments
-	for k := 0; k < np; k++ {
+	grp := o.Groups[cpu]
+	for k := 0; k < len(P); k++ {
 		A := G[P[k][0]]
 		B := G[P[k][1]]
 		a := G[z+P[k][0]]
 		b := G[z+P[k][1]]
-		o.Tournament(A, B, a, b, o.Groups[cpu].Metrics)
+		fA, fB := A.Ova[0], B.Ova[0]
+		replacedA, replacedB := o.tournament(A, B, a, b, grp.Metrics, rng)
+		grp.Noffspring += 2
+		if replacedA {
+			grp.Nsuccess++
+			if grp.deMem != nil {
+				grp.deMem.success(A.DEF, A.DEC, fA-A.Ova[0])
+			}
+		}
+		if replacedB {
+			grp.Nsuccess++
+			if grp.deMem != nil {
+				grp.deMem.success(B.DEF, B.DEC, fB-B.Ova[0])
+			}
+		}
+	}
+
+	// update success-history memories of F and CR
+	if grp.deMem != nil {
+		grp.deMem.update()
 	}
-	return
 }
 
-// Tournament performs the tournament among 4 individuals
-func (o *Optimiser) Tournament(A, B, a, b *Solution, m *Metrics) {
-	dAa := A.Distance(a, m.Fmin, m.Fmax, m.Imin, m.Imax)
-	dAb := A.Distance(b, m.Fmin, m.Fmax, m.Imin, m.Imax)
-	dBa := B.Distance(a, m.Fmin, m.Fmax, m.Imin, m.Imax)
-	dBb := B.Distance(b, m.Fmin, m.Fmax, m.Imin, m.Imax)
-	if dAa+dBb < dAb+dBa {
-		if !A.Fight(a) {
-			a.CopyInto(A)
+// markElite marks the Nelite best feasible solutions as Elite; sorted by front and then ova[0]
+func (o *Optimiser) markElite(sols []*Solution) {
+	var feasible []*Solution
+	for _, sol := range sols {
+		sol.Elite = false
+		if sol.Feasible() {
+			feasible = append(feasible, sol)
 		}
-		if !B.Fight(b) {
-			b.CopyInto(B)
+	}
+	sort.SliceStable(feasible, func(i, j int) bool {
+		if feasible[i].FrontId == feasible[j].FrontId {
+			return feasible[i].Ova[0] < feasible[j].Ova[0]
 		}
+		return feasible[i].FrontId < feasible[j].FrontId
+	})
+	for i := 0; i < o.Nelite && i < len(feasible); i++ {
+		feasible[i].Elite = true
+	}
+}
+
+// exchange exchanges solutions between groups. Protected solutions are not replaced
+//  Output:
+//   migrated -- solutions that have received data from another group
+//  Note: Migration is used if given; otherwise, the exchange is controlled by ExcTour and ExcOne
+func (o *Optimiser) exchange() (migrated []*Solution) {
+	if o.Ncpu < 2 {
 		return
 	}
-	if !A.Fight(b) {
-		b.CopyInto(A)
+	if o.Migration != nil {
+		return o.Migration.Migrate(o)
 	}
-	if !B.Fight(a) {
-		a.CopyInto(B)
+
+	// exchange via tournament
+	if o.ExcTour {
+		for i := 0; i < o.Ncpu; i++ {
+			j := (i + 1) % o.Ncpu
+			I := o.Rnd.IntGetUnique(o.Groups[i].Indices, 2)
+			J := o.Rnd.IntGetUnique(o.Groups[j].Indices, 2)
+			A, B := o.Groups[i].All[I[0]], o.Groups[i].All[I[1]]
+			a, b := o.Groups[j].All[J[0]], o.Groups[j].All[J[1]]
+			replacedA, replacedB := o.tournament(A, B, a, b, o.Metrics, o.Rnd)
+			if replacedA {
+				migrated = append(migrated, A)
+			}
+			if replacedB {
+				migrated = append(migrated, B)
+			}
+		}
+	}
+
+	// exchange one randomly
+	if o.ExcOne {
+		o.Rnd.IntGetGroups(o.cpupairs, utl.IntRange(o.Ncpu))
+		for _, pair := range o.cpupairs {
+			i, j := pair[0], pair[1]
+			n := utl.Imin(o.Groups[i].Ncur, o.Groups[j].Ncur)
+			free := make([]int, 0, n)
+			for k := 0; k < n; k++ {
+				if !o.Groups[i].All[k].Protected() && !o.Groups[j].All[k].Protected() {
+					free = append(free, k)
+				}
+			}
+			if len(free) == 0 {
+				continue
+			}
+			k := free[o.Rnd.Int(0, len(free)-1)]
+			A := o.Groups[i].All[k]
+			B := o.Groups[j].All[k]
+			B.CopyInto(o.tmp)
+			A.CopyInto(B)
+			o.tmp.CopyInto(A)
+			migrated = append(migrated, A, B)
+		}
 	}
+
+	// remove duplicates
+	unique := make(map[*Solution]bool)
+	n := 0
+	for _, sol := range migrated {
+		if !unique[sol] {
+			unique[sol] = true
+			migrated[n] = sol
+			n++
+		}
+	}
+	return migrated[:n]
 }
 
-// auxiliary //////////////////////////////////////////////////////////////////////////////////////
+// initTerminators initialises the termination criteria
+func (o *Optimiser) initTerminators() {
+	o.StopReason = ""
+	for _, t := range o.Terminators {
+		t.Init(o)
+	}
+}
+
+// terminated checks the termination criteria and records the reason for stopping
+func (o *Optimiser) terminated() bool {
+	for _, t := range o.Terminators {
+		if t.Done(o) {
+			o.StopReason = t.Name()
+			return true
+		}
+	}
+	return false
+}
+
+// initRnd (re)initialises the random numbers streams of the optimiser and groups with Seed
+func (o *Optimiser) initRnd() {
+	o.Rnd.Init(o.Seed)
+	for _, grp := range o.Groups {
+		grp.Rnd = o.Rnd.Split()
+	}
+}
+
+// recordOva0 records the best feasible ova[0] (INF if there are no feasible solutions)
+func (o *Optimiser) recordOva0() {
+	best := INF
+	for _, sol := range o.Solutions {
+		if sol.Feasible() && sol.Ova[0] < best {
+			best = sol.Ova[0]
+		}
+	}
+	o.iova0++
+	if o.iova0 < len(o.ova0) {
+		o.ova0[o.iova0] = best
+	} else {
+		o.ova0 = append(o.ova0, best)
+	}
+}
+
+// stagnated tells whether the best feasible ova[0] has improved by no more than tol during the
+// last nexc exchange periods
+func (o *Optimiser) stagnated(nexc int, tol float64) bool {
+	if nexc < 1 || o.iova0 < nexc {
+		return false
+	}
+	old, cur := o.ova0[o.iova0-nexc], o.ova0[o.iova0]
+	if cur >= INF {
+		return false
+	}
+	return old-cur <= tol
+}
+
+// resetCounters resets the number of function evaluations, failures and cache hits
+func (o *Optimiser) resetCounters() {
+	if o.Cache != nil {
+		o.Cache.ResetHits()
+	}
+	for _, grp := range o.Groups {
+		grp.Nfeval, grp.nfailed, grp.err = 0, 0, nil
+		grp.Noffspring, grp.Nsuccess = 0, 0
+	}
+	o.Nfeval = 0
+	o.Nfailed = 0
+}
 
 // generate_solutions generate solutions
-func (o *Optimiser) generate_solutions(reset bool) {
+func (o *Optimiser) generate_solutions(reset bool) (err error) {
 
 	// benchmark
 	t0 := gotime.Now()
@@ -342,52 +875,86 @@ func (o *Optimiser) generate_solutions(reset bool) {
 		}()
 	}
 
+	// evaluation is postponed to Tell if there is no objective function
+	o.askIni = o.ObjFunc == nil && o.ObjFuncErr == nil && o.BatchObjFunc == nil
+	o.askDone = false
+	stop := o.startWorkers()
+	defer stop()
+
+	// keep fixed solutions. they are restored after calling the generator and are not re-evaluated
+	fixed := make(map[*Solution]*Solution)
+	for _, sol := range o.Solutions {
+		sol.Elite = false
+		if reset && sol.Fixed {
+			fixed[sol] = NewSolution(sol.Id, 0, &o.Parameters)
+			sol.CopyInto(fixed[sol])
+		}
+	}
+	// seeds replace generated solutions before the evaluation
+	slots, err := o.seedSlots()
+	if err != nil {
+		return chk.Err("cannot place seeds:\n%v", err)
+	}
+	restore := func(sols []*Solution) (free []*Solution) {
+		for _, sol := range sols {
+			if backup, ok := fixed[sol]; ok {
+				backup.CopyInto(sol)
+				sol.Fixed = true
+				continue
+			}
+			if k, ok := slots[sol]; ok {
+				o.applySeed(sol, k)
+			}
+			free = append(free, sol)
+		}
+		return
+	}
+
 	// generate
 	if o.GenAll {
-		o.Generator(o.Solutions, &o.Parameters, reset)
-		for _, sol := range o.Solutions {
-			o.ObjFunc(sol, 0)
+		o.generator(o.Solutions, &o.Parameters, reset, o.Rnd)
+		free := restore(o.Solutions)
+		if !o.askIni {
+			o.Nfeval += o.evaluate(free, 0)
 		}
 	} else {
 		done := make(chan int, o.Ncpu)
 		for icpu := 0; icpu < o.Ncpu; icpu++ {
 			go func(cpu int) {
-				start, endp1 := (cpu*o.Nsol)/o.Ncpu, ((cpu+1)*o.Nsol)/o.Ncpu
+				start, endp1 := o.groupRange(cpu)
 				sols := o.Solutions[start:endp1]
-				o.Generator(sols, &o.Parameters, reset)
-				for _, sol := range sols {
-					o.ObjFunc(sol, cpu)
+				o.generator(sols, &o.Parameters, reset, o.Groups[cpu].Rnd)
+				free := restore(sols)
+				nfeval := 0
+				if !o.askIni {
+					nfeval = o.evaluate(free, cpu)
 				}
-				done <- 1
+				o.Groups[cpu].Nfeval += nfeval
+				done <- nfeval
 			}(icpu)
 		}
 		for cpu := 0; cpu < o.Ncpu; cpu++ {
-			<-done
+			o.Nfeval += <-done
 		}
 	}
 	tgen = gotime.Now()
 
 	// metrics
 	o.iova0 = -1
-	o.Nfeval = o.Nsol
-	o.Metrics.Compute(o.Solutions)
+	o.Nhits = 0
+	if o.Cache != nil {
+		o.Nhits = o.Cache.Hits()
+	}
+	if !o.askIni {
+		o.Metrics.Compute(o.Solutions)
+	}
+	err = o.collectFailures()
+	if err != nil {
+		return chk.Err("cannot generate trial solutions:\n%v", err)
+	}
 
 	// meshes
-	if o.Nflt > 1 && o.UseMesh {
-		Xi, Xj := make([]float64, o.Nsol), make([]float64, o.Nsol)
-		o.Meshes = make([][]*Mesh, o.Nflt-1)
-		for i := 0; i < o.Nflt-1; i++ {
-			o.Meshes[i] = make([]*Mesh, o.Nflt)
-			for k, s := range o.Solutions {
-				Xi[k] = s.Flt[i]
-			}
-			for j := i + 1; j < o.Nflt; j++ {
-				for k, s := range o.Solutions {
-					Xj[k] = s.Flt[j]
-				}
-				o.Meshes[i][j].V, o.Meshes[i][j].C = tri.Delaunay(Xi, Xj, false)
-			}
-		}
-	}
+	o.buildMeshes()
 	tmsh = gotime.Now()
+	return
 }
@@ -6,12 +6,37 @@ package goga
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"strings"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
-	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
 )
 
+// ParamError holds a problem with one parameter
+type ParamError struct {
+	Field string // name of parameter; e.g. "Nsol"
+	Msg   string // description of problem
+}
+
+// Error returns the description of the problem, including the name of the parameter
+func (o *ParamError) Error() string {
+	return o.Field + ": " + o.Msg
+}
+
+// ParamErrors collects all problems with parameters
+type ParamErrors []*ParamError
+
+// Error returns the descriptions of all problems; one per line
+func (o ParamErrors) Error() string {
+	msgs := make([]string, len(o))
+	for i, e := range o {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
 // Parameters hold all configuration parameters
 type Parameters struct {
 
@@ -19,7 +44,7 @@ type Parameters struct {
 	Nova int // number of objective values
 	Noor int // number of out-of-range values
 	Nsol int // total number of solutions
-	Ncpu int // number of cpus
+	Ncpu int // number of cpus; i.e. groups (islands) evolved in parallel. see also Nworkers
 
 	// time
 	Tmax  int // final time
@@ -36,15 +61,54 @@ type Parameters struct {
 	Verbose  bool    // show messages
 	VerbStat bool    // show messages in Stat
 	VerbTime bool    // show time messages
-	GenAll   bool    // generate all solutions together; i.e. not within each group/CPU
+	GenAll   bool    // generate all solutions together; i.e. not within each group/CPU. always true if UseMesh==true
 	Nsamples int     // run many samples
 	BinInt   int     // flag that integers represent binary numbers if BinInt > 0; thus Nint=BinInt
 	ClearFlt bool    // clear flt if corresponding int is 0
 	ExcTour  bool    // use exchange via tournament
 	ExcOne   bool    // use exchange one randomly
+	Nelite   int     // number of best feasible solutions in each group protected from being replaced
 	NormFlt  bool    // normalise float values
-	UseMesh  bool    // use meshes to control points movement
+	UseMesh  bool    // use meshes to control points movement. CalcDerived sets GenAll=true and increases Nsol by NumExtraSols
 	Nbry     int     // number of points along boundary / per iFlt (only if UseMesh==true)
+	MeshPm   float64 // probability of moving offspring within the cells around parents (only if UseMesh==true)
+
+	// differential evolution (default crossover of floats)
+	DEstrategy string  // mutation strategy: "rand/1", "best/1", "current-to-best/1", "rand/2" or "current-to-pbest/1"
+	DEcross    string  // crossover: "bin" (binomial) or "exp" (exponential)
+	DEF        float64 // scale factor F; zero means that F is randomly drawn from [0,1] for each offspring
+	DEp        float64 // fraction of the best solutions of the group used by "current-to-pbest/1"
+	DEadapt    string  // self-adaptation of F and CR carried by each solution: "" (none; use DEF and DEC), "jde" or "shade"
+	DEtau      float64 // probability of regenerating F and CR of each offspring (only if DEadapt == "jde")
+	DEmem      int     // size of the success-history memories of F and CR (only if DEadapt == "shade")
+
+	// evaluation of objective functions
+	Nworkers int // number of goroutines (workers) evaluating the offspring of all groups; zero means each group evaluates its own offspring
+
+	// samples in RunMany
+	NsamplesPll int  // number of samples run at the same time by RunMany on cloned optimisers; see RunMany
+	SampleSeeds bool // RunMany uses Seed+itrial as seed of trial itrial; thus trials do not depend on each other
+
+	// failed evaluations; i.e. the objective function returned an error or NaN values
+	FailPolicy    string // what to do with failed solutions: "infeasible", "resample" or "abort"
+	FailNresample int    // max number of re-samplings of a failed solution before marking it as infeasible
+
+	// restarts when the best feasible ova[0] stagnates (only with Solve)
+	RestartNexc int     // number of exchange periods without improvement to restart; zero means no restarts
+	RestartTol  float64 // minimum improvement of best feasible ova[0] during RestartNexc exchange periods
+	RestartGrow float64 // factor multiplying Nsol at each restart; e.g. 2 (IPOP); 1 means same Nsol
+	RestartMax  int     // maximum number of restarts in one run; zero means no limit
+
+	// external archive of feasible non-dominated solutions
+	ArchSize  int    // maximum number of solutions in archive; zero means no archive
+	ArchPrune string // pruning rule when archive is full: "crowd" or "hv" (hypervolume contribution; only if Nova == 2)
+
+	// convergence history
+	RecHistory bool // record the state of the population at each exchange time; see Stat.History
+
+	// cache of objective values
+	CacheSize int     // maximum number of entries in cache; zero means no cache
+	CacheTol  float64 // tolerance to compare floats in cache; zero means exact comparison
 
 	// crossover and mutation of integers
 	IntPc       float64 // probability of crossover for ints
@@ -52,6 +116,16 @@ type Parameters struct {
 	IntPm       float64 // probability of mutation for ints
 	IntNchanges int     // number of changes during mutation of ints
 
+	// crossover and mutation of floats (only if CxFlt is nil)
+	FltOp   string  // operator for floats: "de" (differential evolution) or "sbx" (SBX crossover and polynomial mutation)
+	FltPc   float64 // probability of SBX crossover of each pair of parents (only if FltOp == "sbx")
+	FltEtac float64 // distribution index of SBX crossover (only if FltOp == "sbx")
+	FltPm   float64 // probability of polynomial mutation of each float; zero means 1/Nflt (only if FltOp == "sbx")
+	FltEtam float64 // distribution index of polynomial mutation (only if FltOp == "sbx")
+
+	// boundary handling of floats
+	FltBound string // strategy for out-of-range floats: "clamp", "reflect", "wrap", "random", "midpoint" (toward the parent) or "resample"
+
 	// range
 	FltMin []float64 // minimum float allowed
 	FltMax []float64 // maximum float allowed
@@ -106,55 +180,251 @@ func (o *Parameters) Default() {
 	o.ClearFlt = false
 	o.ExcTour = true
 	o.ExcOne = true
+	o.Nelite = 0
 	o.NormFlt = false
 	o.UseMesh = false
 	o.Nbry = 3
+	o.MeshPm = 0.5
+
+	// differential evolution
+	o.DEstrategy = "rand/1"
+	o.DEcross = "bin"
+	o.DEF = 0
+	o.DEp = 0.1
+	o.DEadapt = ""
+	o.DEtau = 0.1
+	o.DEmem = 10
+
+	// evaluation of objective functions
+	o.Nworkers = 0
+
+	// samples in RunMany
+	o.NsamplesPll = 1
+	o.SampleSeeds = false
+
+	// failed evaluations
+	o.FailPolicy = "abort"
+	o.FailNresample = 10
+
+	// restarts
+	o.RestartNexc = 0
+	o.RestartTol = 1e-10
+	o.RestartGrow = 2
+	o.RestartMax = 0
+
+	// external archive
+	o.ArchSize = 0
+	o.ArchPrune = "crowd"
+
+	// convergence history
+	o.RecHistory = false
+
+	// cache of objective values
+	o.CacheSize = 0
+	o.CacheTol = 0
 
 	// crossover and mutation of integers
 	o.IntPc = 0.8
 	o.IntNcuts = 1
 	o.IntPm = 0.01
 	o.IntNchanges = 1
+
+	// crossover and mutation of floats
+	o.FltOp = "de"
+	o.FltPc = 0.9
+	o.FltEtac = 15
+	o.FltPm = 0
+	o.FltEtam = 20
+
+	// boundary handling of floats
+	o.FltBound = "clamp"
 }
 
 // Read reads configuration parameters from JSON file
 func (o *Parameters) Read(filenamepath string) {
+	err := o.ReadErr(filenamepath)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}
+
+// ReadErr reads configuration parameters from JSON file and returns an error instead of panicking
+func (o *Parameters) ReadErr(filenamepath string) (err error) {
 	o.Default()
-	b := io.ReadFile(filenamepath)
-	err := json.Unmarshal(b, o)
+	b, err := ioutil.ReadFile(filenamepath)
 	if err != nil {
-		chk.Panic("cannot unmarshal parameters file %q", filenamepath)
+		return chk.Err("cannot read parameters file %q:\n%v", filenamepath, err)
+	}
+	err = json.Unmarshal(b, o)
+	if err != nil {
+		return chk.Err("cannot unmarshal parameters file %q:\n%v", filenamepath, err)
 	}
 	return
 }
 
+// Validate checks the consistency of parameters, before CalcDerived is called
+//  Output:
+//   err -- nil or ParamErrors with all problems found
+func (o *Parameters) Validate() error {
+	var errs ParamErrors
+	add := func(field, msg string, args ...interface{}) {
+		errs = append(errs, &ParamError{field, io.Sf(msg, args...)})
+	}
+	if o.Nova < 1 {
+		add("Nova", "number of objective values must be greater than 0. Nova = %d is invalid", o.Nova)
+	}
+	if o.Nsol < 6 {
+		add("Nsol", "number of solutions must greater than 6. Nsol = %d is invalid", o.Nsol)
+	}
+	if o.Ncpu > 1 && o.Ncpu > o.Nsol/2 {
+		add("Ncpu", "number of CPU must be smaller than or equal to half the number of solutions. Ncpu=%d > Nsol/2=%d", o.Ncpu, o.Nsol/2)
+	}
+	if o.Nelite < 0 {
+		add("Nelite", "number of elite solutions must be non-negative. Nelite = %d is invalid", o.Nelite)
+	}
+	nint := len(o.IntMin)
+	if o.BinInt > 0 {
+		nint = o.BinInt
+	}
+	if len(o.FltMin) == 0 && nint == 0 {
+		add("FltMin", "either floats and ints must be set (via FltMin/Max or IntMin/Max)")
+	}
+	if len(o.FltMax) != len(o.FltMin) {
+		add("FltMax", "length of FltMax must be equal to length of FltMin. %d != %d", len(o.FltMax), len(o.FltMin))
+	} else {
+		for i := range o.FltMin {
+			if o.FltMin[i] > o.FltMax[i] {
+				add("FltMax", "FltMax[%d] must be greater than or equal to FltMin[%d]. %g < %g", i, i, o.FltMax[i], o.FltMin[i])
+			}
+		}
+	}
+	if o.BinInt == 0 && len(o.IntMax) != len(o.IntMin) {
+		add("IntMax", "length of IntMax must be equal to length of IntMin. %d != %d", len(o.IntMax), len(o.IntMin))
+	} else if o.BinInt == 0 {
+		for i := range o.IntMin {
+			if o.IntMin[i] > o.IntMax[i] {
+				add("IntMax", "IntMax[%d] must be greater than or equal to IntMin[%d]. %d < %d", i, i, o.IntMax[i], o.IntMin[i])
+			}
+		}
+	}
+	switch o.DEstrategy {
+	case "rand/1", "best/1", "current-to-best/1", "rand/2", "current-to-pbest/1":
+	default:
+		add("DEstrategy", "strategy of differential evolution must be \"rand/1\", \"best/1\", \"current-to-best/1\", \"rand/2\" or \"current-to-pbest/1\". DEstrategy = %q is invalid", o.DEstrategy)
+	}
+	switch o.DEcross {
+	case "bin", "exp":
+	default:
+		add("DEcross", "crossover of differential evolution must be \"bin\" or \"exp\". DEcross = %q is invalid", o.DEcross)
+	}
+	if o.DEF < 0 {
+		add("DEF", "scale factor of differential evolution must be non-negative. DEF = %g is invalid", o.DEF)
+	}
+	if o.DEp <= 0 || o.DEp > 1 {
+		add("DEp", "fraction of best solutions for current-to-pbest/1 must be in (0,1]. DEp = %g is invalid", o.DEp)
+	}
+	switch o.DEadapt {
+	case "", "jde", "shade":
+	default:
+		add("DEadapt", "self-adaptation of differential evolution must be \"\", \"jde\" or \"shade\". DEadapt = %q is invalid", o.DEadapt)
+	}
+	if o.DEtau < 0 || o.DEtau > 1 {
+		add("DEtau", "probability of regenerating F and CR must be in [0,1]. DEtau = %g is invalid", o.DEtau)
+	}
+	if o.DEadapt == "shade" && o.DEmem < 1 {
+		add("DEmem", "size of the success-history memories must be positive. DEmem = %d is invalid", o.DEmem)
+	}
+	switch o.FltOp {
+	case "de", "sbx":
+	default:
+		add("FltOp", "operator for floats must be \"de\" or \"sbx\". FltOp = %q is invalid", o.FltOp)
+	}
+	if o.FltPc < 0 || o.FltPc > 1 || o.FltPm < 0 || o.FltPm > 1 {
+		add("FltPc", "probabilities of crossover and mutation of floats must be in [0,1]. FltPc = %g and FltPm = %g are invalid", o.FltPc, o.FltPm)
+	}
+	if o.FltEtac < 0 || o.FltEtam < 0 {
+		add("FltEtac", "distribution indices of crossover and mutation of floats must be non-negative. FltEtac = %g and FltEtam = %g are invalid", o.FltEtac, o.FltEtam)
+	}
+	switch o.FltBound {
+	case "clamp", "reflect", "wrap", "random", "midpoint", "resample":
+	default:
+		add("FltBound", "boundary handling of floats must be \"clamp\", \"reflect\", \"wrap\", \"random\", \"midpoint\" or \"resample\". FltBound = %q is invalid", o.FltBound)
+	}
+	if o.Nworkers < 0 {
+		add("Nworkers", "number of workers must be non-negative. Nworkers = %d is invalid", o.Nworkers)
+	}
+	if o.NsamplesPll < 0 {
+		add("NsamplesPll", "number of samples run at the same time must be non-negative. NsamplesPll = %d is invalid", o.NsamplesPll)
+	}
+	switch o.FailPolicy {
+	case "infeasible", "resample", "abort":
+	default:
+		add("FailPolicy", "policy for failed evaluations must be \"infeasible\", \"resample\" or \"abort\". FailPolicy = %q is invalid", o.FailPolicy)
+	}
+	if o.FailNresample < 0 {
+		add("FailNresample", "max number of re-samplings of a failed solution must be non-negative. FailNresample = %d is invalid", o.FailNresample)
+	}
+	if o.RestartNexc < 0 {
+		add("RestartNexc", "number of exchange periods to restart must be non-negative. RestartNexc = %d is invalid", o.RestartNexc)
+	}
+	if o.RestartTol < 0 {
+		add("RestartTol", "tolerance to restart must be non-negative. RestartTol = %g is invalid", o.RestartTol)
+	}
+	if o.RestartGrow < 1 {
+		add("RestartGrow", "growth factor of Nsol at restarts must be greater than or equal to 1. RestartGrow = %g is invalid", o.RestartGrow)
+	}
+	if o.RestartNexc > 0 && o.RestartGrow > 1 && o.UseMesh {
+		add("RestartGrow", "growth of Nsol at restarts cannot be used with UseMesh. RestartGrow = %g is invalid", o.RestartGrow)
+	}
+	if o.RestartMax < 0 {
+		add("RestartMax", "maximum number of restarts must be non-negative. RestartMax = %d is invalid", o.RestartMax)
+	}
+	if o.ArchSize < 0 {
+		add("ArchSize", "maximum number of solutions in archive must be non-negative. ArchSize = %d is invalid", o.ArchSize)
+	}
+	switch o.ArchPrune {
+	case "crowd", "hv":
+	default:
+		add("ArchPrune", "pruning rule of archive must be \"crowd\" or \"hv\". ArchPrune = %q is invalid", o.ArchPrune)
+	}
+	if o.CacheTol < 0 {
+		add("CacheTol", "tolerance for cache must be non-negative. CacheTol = %g is invalid", o.CacheTol)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 // CalcDerived computes derived variables and checks consistency
+//  Note: CalcDerived panics if Validate returns an error
 func (o *Parameters) CalcDerived() {
 
 	// check
-	if o.Nova < 1 {
-		chk.Panic("number of objective values (nova) must be greater than 0")
-	}
-	if o.Nsol < 6 {
-		chk.Panic("number of solutions must greater than 6. Nsol = %d is invalid", o.Nsol)
+	err := o.Validate()
+	if err != nil {
+		chk.Panic("invalid parameters:\n%v", err)
 	}
 	if o.Ncpu < 2 {
 		o.Ncpu = 1
 		o.Pll = false
 		o.DtExc = 1
 	}
-	if o.Ncpu > o.Nsol/2 {
-		chk.Panic("number of CPU must be smaller than or equal to half the number of solutions. Ncpu=%d > Nsol/2=%d", o.Ncpu, o.Nsol/2)
-	}
 	if o.Tmax < 1 {
 		o.Tmax = 1
 	}
 	if o.DtExc < 1 {
-		o.DtExc = o.Tmax / 10
+		o.DtExc = utl.Imax(o.Tmax/10, 1)
 	}
 	if o.DtOut < 1 {
 		o.DtOut = o.Tmax / 5
 	}
+	if o.CacheSize < 0 {
+		o.CacheSize = 0
+	}
+	if o.NsamplesPll < 1 {
+		o.NsamplesPll = 1
+	}
 
 	// derived
 	o.Nflt = len(o.FltMin)
@@ -162,13 +432,9 @@ func (o *Parameters) CalcDerived() {
 	if o.BinInt > 0 {
 		o.Nint = o.BinInt
 	}
-	if o.Nflt == 0 && o.Nint == 0 {
-		chk.Panic("either floats and ints must be set (via FltMin/Max or IntMin/Max)")
-	}
 
 	// floats
 	if o.Nflt > 0 {
-		chk.IntAssert(len(o.FltMax), o.Nflt)
 		o.DelFlt = make([]float64, o.Nflt)
 		for i := 0; i < o.Nflt; i++ {
 			o.DelFlt[i] = o.FltMax[i] - o.FltMin[i]
@@ -180,6 +446,7 @@ func (o *Parameters) CalcDerived() {
 		o.UseMesh = false
 	}
 	if o.UseMesh {
+		o.GenAll = true // boundary solutions are generated at the end of Solutions
 		if o.Nbry < 2 {
 			o.Nbry = 2
 		}
@@ -192,7 +459,6 @@ func (o *Parameters) CalcDerived() {
 
 	// generic ints
 	if o.BinInt == 0 && o.Nint > 0 {
-		chk.IntAssert(len(o.IntMax), o.Nint)
 		o.DelInt = make([]int, o.Nint)
 		for i := 0; i < o.Nint; i++ {
 			o.DelInt[i] = o.IntMax[i] - o.IntMin[i]
@@ -212,12 +478,24 @@ func (o *Parameters) CalcDerived() {
 		}
 	}
 
-	// initialise random numbers generator
-	rnd.Init(o.Seed)
 }
 
-// EnforceRange makes sure x is within given range
+// EnforceRange makes sure x is within given range according to FltBound. The middle of the range
+// is taken as parent for "midpoint"; whereas "random" and "resample" clamp x (see BoundFlt)
 func (o *Parameters) EnforceRange(i int, x float64) float64 {
+	return o.BoundFlt(i, x, (o.FltMin[i]+o.FltMax[i])/2.0, nil)
+}
+
+// BoundFlt brings the i-th float x of an offspring of parent back into range according to
+// FltBound. The optimiser applies it to the results of all operators for floats
+//  Note: "resample" clamps x because re-applying the operators is up to the caller. "random"
+//        clamps x if rng is nil
+func (o *Parameters) BoundFlt(i int, x, parent float64, rng *Rnd) float64 {
+	return applyBound(x, parent, o.FltMin[i], o.FltMax[i], o.FltBound, rng)
+}
+
+// clampFlt makes sure the i-th float x is within given range by clamping x regardless of FltBound
+func (o *Parameters) clampFlt(i int, x float64) float64 {
 	if x < o.FltMin[i] {
 		return o.FltMin[i]
 	}
@@ -227,6 +505,16 @@ func (o *Parameters) EnforceRange(i int, x float64) float64 {
 	return x
 }
 
+// fltInRange tells whether all floats in x are within given range
+func (o *Parameters) fltInRange(x []float64) bool {
+	for i := 0; i < o.Nflt; i++ {
+		if x[i] < o.FltMin[i] || x[i] > o.FltMax[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Normalise4 normalises x ∈ [xmin,xmax] values into r ∈ [0,1]
 func (o *Parameters) Normalise4(x, x0, x1, x2 []float64) (r, r0, r1, r2 []float64) {
 	if o.Nflt < 1 {
@@ -248,6 +536,23 @@ func (o *Parameters) Normalise4(x, x0, x1, x2 []float64) (r, r0, r1, r2 []float6
 	return
 }
 
+// Normalise1 returns a copy of x ∈ [xmin,xmax] normalised into r ∈ [0,1]. Floats with zero range
+// are set to 0
+func (o *Parameters) Normalise1(x []float64) (r []float64) {
+	r = make([]float64, len(x))
+	copy(r, x)
+	if o.NormFlt {
+		for i := 0; i < len(x); i++ {
+			if o.DelFlt[i] > 0 {
+				r[i] = (x[i] - o.FltMin[i]) / o.DelFlt[i]
+			} else {
+				r[i] = 0
+			}
+		}
+	}
+	return
+}
+
 // DeNormalise1 de-normalises r ∈ [0,1] values into x ∈ [xmin,xmax]
 //  Output: r becomes x
 func (o *Parameters) DeNormalise1(r []float64) {
@@ -296,9 +601,72 @@ func (o *Parameters) LogParams() (l string) {
 		"clear flt if corresponding int is 0", "ClearFlt", o.ClearFlt,
 		"use exchange via tournament", "ExcTour", o.ExcTour,
 		"use exchange one randomly", "ExcOne", o.ExcOne,
+		"number of protected best feasible solutions per group", "Nelite", o.Nelite,
 		"normalise float values", "NormFlt", o.NormFlt,
 		"use meshes to control points movement", "UseMesh", o.UseMesh,
 		"number of points along boundary / per iFlt (only if UseMesh==true)", "Nbry", o.Nbry,
+		"probability of moving offspring within mesh (only if UseMesh==true)", "MeshPm", o.MeshPm,
+	)
+
+	// differential evolution
+	l += "\n"
+	l += io.ArgsTable("DIFFERENTIAL EVOLUTION",
+		"mutation strategy", "DEstrategy", o.DEstrategy,
+		"crossover: 'bin' or 'exp'", "DEcross", o.DEcross,
+		"scale factor F (0 means random)", "DEF", o.DEF,
+		"fraction of best solutions for current-to-pbest/1", "DEp", o.DEp,
+		"self-adaptation of F and CR: '', 'jde' or 'shade'", "DEadapt", o.DEadapt,
+		"probability of regenerating F and CR in jDE", "DEtau", o.DEtau,
+		"size of success-history memories in SHADE", "DEmem", o.DEmem,
+	)
+
+	// evaluation of objective functions
+	l += "\n"
+	l += io.ArgsTable("EVALUATION OF OBJECTIVE FUNCTIONS",
+		"number of workers evaluating the offspring of all groups", "Nworkers", o.Nworkers,
+	)
+
+	// samples in RunMany
+	l += "\n"
+	l += io.ArgsTable("SAMPLES IN RUNMANY",
+		"number of samples run at the same time", "NsamplesPll", o.NsamplesPll,
+		"use Seed+itrial as seed of each trial", "SampleSeeds", o.SampleSeeds,
+	)
+
+	// failed evaluations
+	l += "\n"
+	l += io.ArgsTable("FAILED EVALUATIONS",
+		"what to do with failed solutions", "FailPolicy", o.FailPolicy,
+		"max number of re-samplings of a failed solution", "FailNresample", o.FailNresample,
+	)
+
+	// restarts
+	l += "\n"
+	l += io.ArgsTable("RESTARTS",
+		"number of exchange periods without improvement to restart", "RestartNexc", o.RestartNexc,
+		"minimum improvement of best feasible ova[0]", "RestartTol", o.RestartTol,
+		"factor multiplying Nsol at each restart", "RestartGrow", o.RestartGrow,
+		"maximum number of restarts in one run", "RestartMax", o.RestartMax,
+	)
+
+	// external archive
+	l += "\n"
+	l += io.ArgsTable("EXTERNAL ARCHIVE",
+		"maximum number of solutions in archive", "ArchSize", o.ArchSize,
+		"pruning rule when archive is full", "ArchPrune", o.ArchPrune,
+	)
+
+	// convergence history
+	l += "\n"
+	l += io.ArgsTable("CONVERGENCE HISTORY",
+		"record convergence history", "RecHistory", o.RecHistory,
+	)
+
+	// cache of objective values
+	l += "\n"
+	l += io.ArgsTable("CACHE OF OBJECTIVE VALUES",
+		"maximum number of entries in cache", "CacheSize", o.CacheSize,
+		"tolerance to compare floats in cache", "CacheTol", o.CacheTol,
 	)
 
 	// crossover and mutation of integers
@@ -310,6 +678,17 @@ func (o *Parameters) LogParams() (l string) {
 		"number of changes during mutation of ints", "IntNchanges", o.IntNchanges,
 	)
 
+	// crossover and mutation of floats
+	l += "\n"
+	l += io.ArgsTable("CROSSOVER AND MUTATION OF FLOATS",
+		"operator for floats: 'de' or 'sbx'", "FltOp", o.FltOp,
+		"probability of SBX crossover", "FltPc", o.FltPc,
+		"distribution index of SBX crossover", "FltEtac", o.FltEtac,
+		"probability of polynomial mutation (0 means 1/Nflt)", "FltPm", o.FltPm,
+		"distribution index of polynomial mutation", "FltEtam", o.FltEtam,
+		"boundary handling of floats", "FltBound", o.FltBound,
+	)
+
 	// derived
 	l += "\n"
 	l += io.ArgsTable("DERIVED",
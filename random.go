@@ -0,0 +1,290 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"math/bits"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/cpmech/gosl/utl"
+)
+
+// Rnd implements a stream of pseudo random numbers. Each Optimiser and each Group owns one stream;
+// thus, runs are reproducible regardless of the scheduling of goroutines.
+//  Note: gosl/rnd only draws from the global source of math/rand; thus it cannot provide one stream
+//        per optimiser. The source of math/rand.New cannot be saved in checkpoints either; hence
+//        the generator here is SplitMix64, whose state is a single number that can be saved and restored
+type Rnd struct {
+	State uint64 // state of generator
+}
+
+// defaultRnd is the stream used by the functions that do not take a stream; e.g. GenTrialSolutions
+// and CxInt. It is shared by all optimisers; thus, it is protected by defaultMutex
+var (
+	defaultRnd   = NewRnd(0)
+	defaultMutex sync.Mutex
+)
+
+// InitDefaultRnd initialises the stream used by the functions that do not take a stream
+//  Input:
+//   seed -- seed value; use seed <= 0 to use current time
+func InitDefaultRnd(seed int) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultRnd.Init(seed)
+}
+
+// withDefaultRnd calls fcn with the default stream locked
+func withDefaultRnd(fcn func(rng *Rnd)) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	fcn(defaultRnd)
+}
+
+// builtinGenerator returns the version with stream of a built-in generator; e.g.
+// GenTrialSolutionsRng for GenTrialSolutions. nil is returned for other generators
+func builtinGenerator(gen Generator_t) GeneratorRng_t {
+	if sameFunc(gen, GenTrialSolutions) {
+		return GenTrialSolutionsRng
+	}
+	return nil
+}
+
+// builtinCxInt returns the version with stream of a built-in crossover function for ints; e.g.
+// CxIntRng for CxInt. nil is returned for other functions
+func builtinCxInt(cx CxInt_t) CxIntRng_t {
+	switch {
+	case sameFunc(cx, CxInt):
+		return CxIntRng
+	case sameFunc(cx, CxIntOrd):
+		return CxIntOrdRng
+	}
+	return nil
+}
+
+// builtinMtInt returns the version with stream of a built-in mutation function for ints; e.g.
+// MtIntRng for MtInt. nil is returned for other functions
+func builtinMtInt(mt MtInt_t) MtIntRng_t {
+	switch {
+	case sameFunc(mt, MtInt):
+		return MtIntRng
+	case sameFunc(mt, MtIntBin):
+		return MtIntBinRng
+	case sameFunc(mt, MtIntOrd):
+		return MtIntOrdRng
+	}
+	return nil
+}
+
+// sameFunc tells whether the functions a and b have the same code. nil functions are never the same
+func sameFunc(a, b interface{}) bool {
+	pa, pb := reflect.ValueOf(a).Pointer(), reflect.ValueOf(b).Pointer()
+	return pa != 0 && pa == pb
+}
+
+// NewRnd allocates a new stream of random numbers
+//  Input:
+//   seed -- seed value; use seed <= 0 to use current time
+func NewRnd(seed int) (o *Rnd) {
+	o = new(Rnd)
+	o.Init(seed)
+	return
+}
+
+// Init initialises the stream of random numbers
+//  Input:
+//   seed -- seed value; use seed <= 0 to use current time
+func (o *Rnd) Init(seed int) {
+	if seed <= 0 {
+		seed = int(time.Now().UnixNano())
+	}
+	o.State = uint64(seed)
+}
+
+// Split returns a new stream seeded by this stream
+func (o *Rnd) Split() *Rnd {
+	return &Rnd{o.Uint64()}
+}
+
+// Uint64 generates a pseudo random 64-bit unsigned integer
+func (o *Rnd) Uint64() uint64 {
+	o.State += 0x9e3779b97f4a7c15
+	z := o.State
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Intn generates a pseudo random integer in [0, n)
+//  Note: using Lemire's multiply-and-reject method; thus, there is no bias
+func (o *Rnd) Intn(n int) int {
+	m := uint64(n)
+	hi, lo := bits.Mul64(o.Uint64(), m)
+	if lo < m {
+		thresh := -m % m
+		for lo < thresh {
+			hi, lo = bits.Mul64(o.Uint64(), m)
+		}
+	}
+	return int(hi)
+}
+
+// Int generates a pseudo random integer between low and high (inclusive)
+func (o *Rnd) Int(low, high int) int {
+	return o.Intn(high-low+1) + low
+}
+
+// Float64 generates a pseudo random real number in [low, high)
+func (o *Rnd) Float64(low, high float64) float64 {
+	return low + (high-low)*float64(o.Uint64()>>11)/(1<<53)
+}
+
+// Normal generates a pseudo random real number with normal distribution N(μ, σ)
+//  Note: using the Box-Muller transform
+func (o *Rnd) Normal(μ, σ float64) float64 {
+	u1 := 1.0 - o.Float64(0, 1) // in (0, 1]
+	u2 := o.Float64(0, 1)
+	return μ + σ*math.Sqrt(-2.0*math.Log(u1))*math.Cos(2.0*math.Pi*u2)
+}
+
+// Cauchy generates a pseudo random real number with Cauchy distribution of location x0 and scale γ
+func (o *Rnd) Cauchy(x0, γ float64) float64 {
+	return x0 + γ*math.Tan(math.Pi*(o.Float64(0, 1)-0.5))
+}
+
+// FlipCoin generates a Bernoulli variable; throw a coin with probability p
+func (o *Rnd) FlipCoin(p float64) bool {
+	if p == 1.0 {
+		return true
+	}
+	if p == 0.0 {
+		return false
+	}
+	return o.Float64(0, 1) <= p
+}
+
+// IntShuffle shuffles a slice of integers
+func (o *Rnd) IntShuffle(values []int) {
+	for i := len(values) - 1; i > 0; i-- {
+		j := o.Intn(i + 1)
+		values[i], values[j] = values[j], values[i]
+	}
+}
+
+// IntGetShuffled returns a shuffled slice of integers
+func (o *Rnd) IntGetShuffled(values []int) (shuffled []int) {
+	shuffled = make([]int, len(values))
+	copy(shuffled, values)
+	o.IntShuffle(shuffled)
+	return
+}
+
+// IntGetUnique randomly selects n items in a list avoiding duplicates
+//  Note: using the 'reservoir sampling' method
+func (o *Rnd) IntGetUnique(values []int, n int) (selected []int) {
+	if n < 1 {
+		return
+	}
+	if n >= len(values) {
+		return o.IntGetShuffled(values)
+	}
+	selected = make([]int, n)
+	copy(selected, values[:n])
+	for i := n; i < len(values); i++ {
+		j := o.Intn(i + 1)
+		if j < n {
+			selected[j] = values[i]
+		}
+	}
+	return
+}
+
+// IntGetUniqueN randomly selects n items from start to endp1-1 avoiding duplicates
+func (o *Rnd) IntGetUniqueN(start, endp1, n int) (selected []int) {
+	return o.IntGetUnique(utl.IntRange2(start, endp1), n)
+}
+
+// IntGetGroups randomly selects indices from pool separating them in groups
+//  Input:
+//    pool -- all ints.
+//  Output:
+//    groups -- [ngroups][size_of_group] pre-allocated slices
+func (o *Rnd) IntGetGroups(groups [][]int, pool []int) {
+	indices := o.IntGetShuffled(pool)
+	k := 0
+	for i := 0; i < len(groups); i++ {
+		for j := 0; j < len(groups[i]); j++ {
+			groups[i][j] = indices[k]
+			k++
+		}
+	}
+}
+
+// LatinIHS generates n points of a Latin hypercube with levels 1 to n in each dimension. The
+// points are selected one after another as in the improved distributed hypercube sampling (IHS)
+// method; i.e. among d·m random candidates made of the m levels still available in each dimension,
+// the selected one is the one whose distance to the nearest point already selected is the closest
+// to the ideal spacing n/n^(1/dim)
+//  Note: this replaces rnd.LatinIHS of gosl, which draws from the global source of math/rand
+//  Input:
+//   dim -- spatial dimension
+//   n   -- number of points to be generated
+//   d   -- duplication factor ≥ 1 (~ 5 is reasonable)
+//  Output:
+//   x   -- [dim][n] points
+func (o *Rnd) LatinIHS(dim, n, d int) (x [][]int) {
+
+	// levels available in each dimension: free[i][:m]
+	x = utl.IntAlloc(dim, n)
+	free := utl.IntAlloc(dim, n)
+	for i := 0; i < dim; i++ {
+		for j := 0; j < n; j++ {
+			free[i][j] = j + 1
+		}
+	}
+	ideal := float64(n) / math.Pow(float64(n), 1.0/float64(dim))
+
+	// select points
+	cand := make([]int, dim) // indices in free of the levels of the candidate
+	best := make([]int, dim) // indices in free of the levels of the best candidate
+	for k := 0; k < n; k++ {
+		m := n - k
+		ncand := d * m
+		if k == 0 || m == 1 {
+			ncand = 1 // first point is random and last point is the only choice
+		}
+		bestDev := INF
+		for c := 0; c < ncand; c++ {
+			for i := 0; i < dim; i++ {
+				cand[i] = o.Intn(m)
+			}
+			dev := 0.0
+			if k > 0 {
+				dmin := INF
+				for j := 0; j < k; j++ {
+					sum := 0.0
+					for i := 0; i < dim; i++ {
+						δ := float64(free[i][cand[i]] - x[i][j])
+						sum += δ * δ
+					}
+					dmin = math.Min(dmin, sum)
+				}
+				dev = math.Abs(math.Sqrt(dmin) - ideal)
+			}
+			if dev < bestDev {
+				bestDev = dev
+				copy(best, cand)
+			}
+		}
+		for i := 0; i < dim; i++ {
+			x[i][k] = free[i][best[i]]
+			free[i][best[i]] = free[i][m-1]
+		}
+	}
+	return
+}
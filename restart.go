@@ -0,0 +1,122 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// RestartData holds information about one restart
+type RestartData struct {
+	Time   int     // time when the restart happened
+	Nfeval int     // number of function evaluations before the restart
+	Nsol   int     // number of solutions before the restart
+	Ova0   float64 // best feasible ova[0] of the population before the restart
+}
+
+// needRestart tells whether the population must be regenerated or not
+func (o *Optimiser) needRestart() bool {
+	if o.RestartNexc < 1 {
+		return false
+	}
+	if o.RestartMax > 0 && len(o.Restarts) >= o.RestartMax {
+		return false
+	}
+	return o.stagnated(o.RestartNexc, o.RestartTol)
+}
+
+// restart regenerates all solutions, except the Fixed ones. Nsol is increased by RestartGrow and
+// the groups are re-built if needed. The number of function evaluations is not reset
+func (o *Optimiser) restart(time int) (err error) {
+
+	// record restart
+	o.Restarts = append(o.Restarts, &RestartData{time, o.Nfeval, o.Nsol, o.ova0[o.iova0]})
+	if o.Verbose {
+		io.Pfyel("\nrestart # %d at time = %d. best ova[0] = %g\n", len(o.Restarts), time, o.ova0[o.iova0])
+	}
+
+	// new number of solutions: multiple of Ncpu
+	nsol := o.Nsol
+	if o.RestartGrow > 1 {
+		nsol = int(math.Ceil(float64(o.Nsol)*o.RestartGrow/float64(o.Ncpu))) * o.Ncpu
+	}
+	resized := nsol != o.Nsol
+	if resized {
+		o.resize(nsol)
+	}
+
+	// generate solutions
+	err = o.generate_solutions(true)
+	if err != nil {
+		return chk.Err("cannot restart:\n%v", err)
+	}
+	if !resized {
+		for cpu := 0; cpu < o.Ncpu; cpu++ {
+			start, _ := o.groupRange(cpu)
+			o.Groups[cpu].resetRange(start, o.Solutions)
+		}
+	}
+	return
+}
+
+// resize re-allocates Solutions, Groups and Metrics with nsol solutions. The Fixed solutions keep
+// their positions (if i < nsol) and the groups keep their random numbers streams
+func (o *Optimiser) resize(nsol int) {
+	sols, groups := o.Solutions, o.Groups
+	o.allocate(nsol)
+	for i, sol := range sols {
+		if sol.Fixed && i < nsol {
+			sol.CopyInto(o.Solutions[i])
+			o.Solutions[i].Fixed = true
+		}
+	}
+	for cpu, grp := range groups {
+		o.Groups[cpu].Rnd = grp.Rnd
+		o.Groups[cpu].Nfeval = grp.Nfeval
+	}
+}
+
+// archiveBest copies the best feasible solution into Best if it is better than the current one
+func (o *Optimiser) archiveBest() {
+	if o.RestartNexc < 1 {
+		return
+	}
+	for _, sol := range o.Solutions {
+		if sol.Feasible() && (o.Best == nil || sol.Ova[0] < o.Best.Ova[0]) {
+			if o.Best == nil {
+				o.Best = NewSolution(sol.Id, 0, &o.Parameters)
+			}
+			sol.CopyInto(o.Best)
+		}
+	}
+}
+
+// restoreBest replaces the worst solution by Best if Best is better than all current solutions;
+// i.e. when the best solution has been found before a restart
+func (o *Optimiser) restoreBest() {
+	if len(o.Restarts) == 0 || o.Best == nil {
+		return
+	}
+	var worst *Solution
+	for _, sol := range o.Solutions {
+		if sol.Feasible() && sol.Ova[0] <= o.Best.Ova[0] {
+			return
+		}
+		if sol.Protected() {
+			continue
+		}
+		if worst == nil || (worst.Feasible() && !sol.Feasible()) || (worst.Feasible() == sol.Feasible() && sol.Ova[0] > worst.Ova[0]) {
+			worst = sol
+		}
+	}
+	if worst == nil {
+		return
+	}
+	o.Best.CopyInto(worst)
+	o.Metrics.Compute(o.Solutions)
+}
@@ -0,0 +1,161 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// ReadSeeds reads the floats and ints of solutions from a results file written by WriteAllValues.
+// The results can be given to SeedFlt and SeedInt to start a new optimisation from them
+//  Input:
+//   filenamepath -- results file; e.g. "/tmp/goga/prob1.res"
+//  Output:
+//   flts -- [nsol][nflt] floats from columns x0, x1, ... (nil if there are no floats)
+//   ints -- [nsol][nint] ints from columns y0, y1, ... (nil if there are no ints)
+func ReadSeeds(filenamepath string) (flts [][]float64, ints [][]int, err error) {
+
+	// read file
+	b, err := ioutil.ReadFile(filenamepath)
+	if err != nil {
+		return nil, nil, chk.Err("cannot read results file %q:\n%v", filenamepath, err)
+	}
+	var rows [][]string
+	for _, line := range strings.Split(string(b), "\n") {
+		r := strings.Fields(line)
+		if len(r) > 0 && !strings.HasPrefix(r[0], "#") {
+			rows = append(rows, r)
+		}
+	}
+	if len(rows) < 1 {
+		return nil, nil, chk.Err("results file %q is empty", filenamepath)
+	}
+
+	// columns
+	col := make(map[string]int)
+	for j, key := range rows[0] {
+		col[key] = j
+	}
+	var cx, cy []int
+	for i := 0; ; i++ {
+		j, ok := col[io.Sf("x%d", i)]
+		if !ok {
+			break
+		}
+		cx = append(cx, j)
+	}
+	for i := 0; ; i++ {
+		j, ok := col[io.Sf("y%d", i)]
+		if !ok {
+			break
+		}
+		cy = append(cy, j)
+	}
+	if len(cx) == 0 && len(cy) == 0 {
+		return nil, nil, chk.Err("results file %q has neither x nor y columns", filenamepath)
+	}
+
+	// values
+	for k, r := range rows[1:] {
+		if len(r) != len(rows[0]) {
+			return nil, nil, chk.Err("row %d of results file %q has %d columns; but %d are required", k, filenamepath, len(r), len(rows[0]))
+		}
+		if len(cx) > 0 {
+			x := make([]float64, len(cx))
+			for i, j := range cx {
+				x[i], err = strconv.ParseFloat(r[j], 64)
+				if err != nil {
+					return nil, nil, chk.Err("cannot parse x%d in row %d of results file %q:\n%v", i, k, filenamepath, err)
+				}
+			}
+			flts = append(flts, x)
+		}
+		if len(cy) > 0 {
+			y := make([]int, len(cy))
+			for i, j := range cy {
+				y[i], err = strconv.Atoi(r[j])
+				if err != nil {
+					return nil, nil, chk.Err("cannot parse y%d in row %d of results file %q:\n%v", i, k, filenamepath, err)
+				}
+			}
+			ints = append(ints, y)
+		}
+	}
+	return
+}
+
+// seedSlots selects the solutions that receive SeedFlt and SeedInt. The seeds are distributed
+// among the groups in a round-robin fashion; Fixed solutions and the boundary solutions of meshes
+// (UseMesh) are skipped and extra seeds are ignored. Seeds are not placed at restarts
+func (o *Optimiser) seedSlots() (slots map[*Solution]int, err error) {
+
+	// check
+	nseeds := utl.Imax(len(o.SeedFlt), len(o.SeedInt))
+	if nseeds == 0 || len(o.Restarts) > 0 {
+		return
+	}
+	if o.Nflt > 0 && len(o.SeedFlt) != nseeds {
+		return nil, chk.Err("number of seeds for floats must be equal to %d. %d is invalid", nseeds, len(o.SeedFlt))
+	}
+	if o.Nint > 0 && len(o.SeedInt) != nseeds {
+		return nil, chk.Err("number of seeds for ints must be equal to %d. %d is invalid", nseeds, len(o.SeedInt))
+	}
+	for k := 0; k < nseeds; k++ {
+		if o.Nflt > 0 && len(o.SeedFlt[k]) != o.Nflt {
+			return nil, chk.Err("seed %d must have %d floats. %d is invalid", k, o.Nflt, len(o.SeedFlt[k]))
+		}
+		if o.Nint > 0 && len(o.SeedInt[k]) != o.Nint {
+			return nil, chk.Err("seed %d must have %d ints. %d is invalid", k, o.Nint, len(o.SeedInt[k]))
+		}
+	}
+
+	// slots. the ranges of groups are the same as in generate_solutions. the boundary solutions of
+	// meshes are the last NumExtraSols ones; they are marked as Fixed by the generator
+	slots = make(map[*Solution]int)
+	nfree := o.Nsol
+	if o.UseMesh {
+		nfree -= o.NumExtraSols
+	}
+	k := 0
+	for r := 0; k < nseeds; r++ {
+		added := false
+		for cpu := 0; cpu < o.Ncpu && k < nseeds; cpu++ {
+			start, endp1 := o.groupRange(cpu)
+			if start+r < endp1 {
+				added = true
+				sol := o.Solutions[start+r]
+				if !sol.Fixed && start+r < nfree {
+					slots[sol] = k
+					k++
+				}
+			}
+		}
+		if !added {
+			break
+		}
+	}
+	return
+}
+
+// applySeed sets the values of seed k into sol. The values are clamped to the ranges in
+// Parameters regardless of FltBound
+func (o *Optimiser) applySeed(sol *Solution, k int) {
+	for i := 0; i < o.Nflt; i++ {
+		sol.Flt[i] = o.clampFlt(i, o.SeedFlt[k][i])
+	}
+	for i := 0; i < o.Nint; i++ {
+		if o.BinInt > 0 {
+			sol.Int[i] = utl.Imin(utl.Imax(o.SeedInt[k][i], 0), 1)
+		} else {
+			sol.Int[i] = utl.Imin(utl.Imax(o.SeedInt[k][i], o.IntMin[i]), o.IntMax[i])
+		}
+	}
+}
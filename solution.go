@@ -9,7 +9,6 @@ import (
 	"sort"
 
 	"github.com/cpmech/gosl/chk"
-	"github.com/cpmech/gosl/rnd"
 	"github.com/cpmech/gosl/utl"
 )
 
@@ -19,7 +18,8 @@ type Solution struct {
 	// essential
 	prms  *Parameters // pointer to parameters
 	Id    int         // identifier (for debugging). future solutions have negative ids
-	Fixed bool        // cannot be changed
+	Fixed bool        // cannot be changed; i.e. never replaced (e.g. pinned by the user)
+	Elite bool        // one of the Nelite best feasible solutions in its group; i.e. not replaced
 	Ova   []float64   // objective values
 	Oor   []float64   // out-of-range values
 	Flt   []float64   // floats
@@ -34,6 +34,10 @@ type Solution struct {
 	DistNeigh float64     // closest neighbour distance
 	Closest   *Solution   // closest neighbour
 
+	// self-adaptive differential evolution (only if DEadapt != ""); zero means not set yet
+	DEF float64 // scale factor F used to create this solution
+	DEC float64 // crossover rate CR used to create this solution
+
 	// auxiliary
 	Aux float64 // auxiliary data to be stored at each solution; e.g. limit state function value
 }
@@ -66,6 +70,7 @@ func (o *Solution) Reset(id int) {
 	// essential
 	o.Id = id
 	o.Fixed = false
+	o.Elite = false
 	utl.Fill(o.Ova, 0)
 	utl.Fill(o.Oor, 0)
 	utl.Fill(o.Flt, 0)
@@ -82,6 +87,10 @@ func (o *Solution) Reset(id int) {
 	o.DistNeigh = 0
 	o.Closest = nil
 
+	// self-adaptive differential evolution
+	o.DEF = 0
+	o.DEC = 0
+
 	// auxiliary
 	o.Aux = 0
 }
@@ -96,13 +105,22 @@ func (o *Solution) Feasible() bool {
 	return true
 }
 
+// Protected tells whether this solution must not be replaced or not; i.e. it is Fixed or Elite
+func (o *Solution) Protected() bool {
+	return o.Fixed || o.Elite
+}
+
 // CopyInto copies essential data into B
+//  Note: the Fixed and Elite flags are not copied because they belong to the position of the
+//        solution in the population and not to its values
 func (A *Solution) CopyInto(B *Solution) {
 	B.Id = A.Id
 	copy(B.Ova, A.Ova)
 	copy(B.Oor, A.Oor)
 	copy(B.Flt, A.Flt)
 	copy(B.Int, A.Int)
+	B.DEF = A.DEF
+	B.DEC = A.DEC
 }
 
 // Distance computes (genotype) distance between A and B
@@ -169,6 +187,12 @@ func (A *Solution) Compare(B *Solution) (A_dominates, B_dominates bool) {
 
 // Fight implements the competition between A and B
 func (A *Solution) Fight(B *Solution) (A_wins bool) {
+	withDefaultRnd(func(rng *Rnd) { A_wins = A.FightRng(B, rng) })
+	return
+}
+
+// FightRng is the same as Fight but uses the given stream of random numbers
+func (A *Solution) FightRng(B *Solution, rng *Rnd) (A_wins bool) {
 
 	// compare solutions
 	A_dom, B_dom := A.Compare(B)
@@ -187,7 +211,7 @@ func (A *Solution) Fight(B *Solution) (A_wins bool) {
 		if B.DistNeigh > A.DistNeigh {
 			return false
 		}
-		return rnd.FlipCoin(0.5)
+		return rng.FlipCoin(0.5)
 	}
 
 	// tie: multi-objective problems: same Pareto front
@@ -198,7 +222,7 @@ func (A *Solution) Fight(B *Solution) (A_wins bool) {
 		if B.DistCrowd > A.DistCrowd {
 			return false
 		}
-		return rnd.FlipCoin(0.5)
+		return rng.FlipCoin(0.5)
 	}
 
 	// tie: multi-objective problems: different Pareto fronts
@@ -214,7 +238,7 @@ func (A *Solution) Fight(B *Solution) (A_wins bool) {
 	if B.DistNeigh > A.DistNeigh {
 		return false
 	}
-	return rnd.FlipCoin(0.5)
+	return rng.FlipCoin(0.5)
 }
 
 // sorting /////////////////////////////////////////////////////////////////////////////////////////
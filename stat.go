@@ -6,8 +6,10 @@ package goga
 
 import (
 	"math"
+	"sync"
 	"time"
 
+	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/rnd"
 	"github.com/cpmech/gosl/utl"
@@ -17,9 +19,14 @@ type Stat struct {
 
 	// stat
 	Nfeval     int             // number of function evaluations
+	Nhits      int             // number of evaluations skipped because the solution was in Cache
+	Nfailed    int             // number of failed evaluations; i.e. error or NaN values
 	SysTimes   []time.Duration // all system times for each run
 	SysTimeAve time.Duration   // average of all system times
 	SysTimeTot time.Duration   // total system (real/CPU) time
+	StopReason string          // why the last Solve stopped: "tmax", "failure", Terminator name or ctx error
+	Restarts   []*RestartData  // restarts performed during the last Solve
+	History    History         // convergence history of the last Solve (only if RecHistory)
 
 	// formatting data for reports
 	RptName         string    // problem name
@@ -57,6 +64,14 @@ type Stat struct {
 	BestOfBestFlt []float64   // [nflt]
 	BestOfBestInt []int       // [nint]
 
+	// RunMany: restarts and archives
+	Nrestarts []int         // number of restarts in each trial [nsamples]
+	Archives  [][]*Solution // solutions in Archive at the end of each trial [nsamples] (only if ArchSize > 0)
+
+	// RunMany: convergence history (only if RecHistory)
+	Histories   []History    // convergence history of each trial [nsamples]
+	HistoryStat *HistoryStat // quartiles of the convergence histories over all trials at each exchange
+
 	// RunMany: checking multi-obj problems
 	F1F0_func      func(f0 float64) float64  // f1(f0) function
 	F1F0_err       []float64                 // max(error(f1))
@@ -66,6 +81,7 @@ type Stat struct {
 	Multi_fcnErr   func(f []float64) float64 // computes Pareto-optimal front error with many OVAs
 	Multi_err      []float64                 // max(error(f[i]))
 	Multi_fStar    [][]float64               // reference points on Pareto front [npoints][nova]
+	Multi_hvRef    []float64                 // reference point to compute hypervolume in History [nova]
 	Multi_IGD      []float64                 // IGD metric
 
 	// RunMany: statistics: F
@@ -95,6 +111,15 @@ type Stat struct {
 //   Input:
 //     dirout -- directory to write files with results [may be ""]
 //     fnkey  -- filename key with results (will add .res) [may be ""]
+//   Note: the first trial runs on the current solutions; e.g. those generated by Init. The
+//         solutions of the other trials are re-generated with Reset. With SampleSeeds, trial i
+//         uses Seed+i; otherwise, all trials use Seed if constantSeed is true or, if it is false,
+//         seeds drawn from a stream initialised with Seed (see trialSeeds).
+//         If NsamplesPll > 1, the trials run at the same time on clones of this optimiser and the
+//         results are the same as in sequential runs. Each trial running at the same time passes
+//         a different range of cpu indices to the objective functions (see ObjFunc_t). The clones
+//         do not call Output or notify Observers; but the state of the last trial is copied into
+//         this optimiser at the end as in sequential runs
 func (o *Optimiser) RunMany(dirout, fnkey string, constantSeed bool) {
 
 	// benchmark
@@ -136,139 +161,108 @@ func (o *Optimiser) RunMany(dirout, fnkey string, constantSeed bool) {
 	o.BestOfBestOva = make([]float64, o.Nova)
 	o.BestOfBestFlt = make([]float64, o.Nflt)
 	o.BestOfBestInt = make([]int, o.Nint)
+	o.Nrestarts = make([]int, o.Nsamples)
+	o.Archives = make([][]*Solution, o.Nsamples)
+	o.Histories = make([]History, o.Nsamples)
 
 	// perform trials
-	for itrial := 0; itrial < o.Nsamples; itrial++ {
-
-		// re-generate solutions
-		o.Nfeval = 0
-		if itrial > 0 {
-			o.Reset(constantSeed)
+	seed := o.Seed
+	defer func() {
+		o.Seed = seed
+	}()
+	results := make([]*trialResult, o.Nsamples)
+	seeds := o.trialSeeds(seed, constantSeed)
+	if o.NsamplesPll > 1 {
+		trials := make(chan int, o.Nsamples)
+		for itrial := 0; itrial < o.Nsamples; itrial++ {
+			trials <- itrial
 		}
-
-		// save initial solutions
-		if fnkey != "" {
-			WriteAllValues(dirout, io.Sf("%s-%04d_ini", fnkey, itrial), o)
+		close(trials)
+		var last *Optimiser
+		var wg sync.WaitGroup
+		for k := 0; k < utl.Imin(o.NsamplesPll, o.Nsamples); k++ {
+			wg.Add(1)
+			go func(k int) {
+				defer wg.Done()
+				for itrial := range trials {
+					c := o.clone()
+					c.cpuOffset = k * utl.Imax(o.Ncpu, o.Nworkers)
+					c.Seed = seeds[itrial]
+					if itrial == 0 {
+						c.adoptTrial(o)
+						c.resetCounters()
+					} else {
+						c.Reset(true)
+					}
+					results[itrial] = c.runTrial(itrial, dirout, fnkey, denominatorL)
+					if itrial == o.Nsamples-1 {
+						last = c
+					}
+				}
+			}(k)
 		}
-
-		// message
-		if o.VerbStat {
-			io.Pf(". . . running trial # %d\n", itrial)
+		wg.Wait()
+		o.adoptTrial(last)
+	} else {
+		for itrial := 0; itrial < o.Nsamples; itrial++ {
+			o.Seed = seeds[itrial]
+			if itrial == 0 {
+				o.resetCounters()
+			} else {
+				o.Reset(true)
+			}
+			results[itrial] = o.runTrial(itrial, dirout, fnkey, denominatorL)
 		}
+		o.Metrics.Compute(o.Solutions) // as in adoptTrial; thus the metrics do not depend on NsamplesPll
+	}
 
-		// solve
-		timeIni := time.Now()
-		o.Solve()
-		o.SysTimes[itrial] = time.Now().Sub(timeIni)
-
-		// sort
-		SortSolutions(o.Solutions, 0)
-
-		// feasible solution
-		if o.Solutions[0].Feasible() {
+	// collect results in the order of trials
+	for itrial, res := range results {
+		o.SysTimes[itrial] = res.sysTime
+		o.Nrestarts[itrial] = res.nrestarts
+		o.Archives[itrial] = res.archive
+		o.Histories[itrial] = res.history
+		if res.best == nil {
+			continue
+		}
 
-			// best solution
-			best := o.Solutions[0]
-			for i := 0; i < o.Nova; i++ {
-				o.BestOvas[i] = append(o.BestOvas[i], best.Ova[i])
-			}
-			for i := 0; i < o.Nflt; i++ {
-				o.BestFlts[i] = append(o.BestFlts[i], best.Flt[i])
-			}
-			for i := 0; i < o.Nint; i++ {
-				o.BestInts[i] = append(o.BestInts[i], best.Int[i])
-			}
+		// best solution
+		best := res.best
+		for i := 0; i < o.Nova; i++ {
+			o.BestOvas[i] = append(o.BestOvas[i], best.Ova[i])
+		}
+		for i := 0; i < o.Nflt; i++ {
+			o.BestFlts[i] = append(o.BestFlts[i], best.Flt[i])
+		}
+		for i := 0; i < o.Nint; i++ {
+			o.BestInts[i] = append(o.BestInts[i], best.Int[i])
+		}
 
-			// best of all trials
-			first_best := len(o.BestOvas[0]) == 1
-			if first_best {
+		// best of all trials
+		first_best := len(o.BestOvas[0]) == 1
+		if first_best {
+			copy(o.BestOfBestOva, best.Ova)
+			copy(o.BestOfBestFlt, best.Flt)
+			copy(o.BestOfBestInt, best.Int)
+		} else {
+			if best.Ova[0] < o.BestOfBestOva[0] {
 				copy(o.BestOfBestOva, best.Ova)
 				copy(o.BestOfBestFlt, best.Flt)
 				copy(o.BestOfBestInt, best.Int)
-			} else {
-				if best.Ova[0] < o.BestOfBestOva[0] {
-					copy(o.BestOfBestOva, best.Ova)
-					copy(o.BestOfBestFlt, best.Flt)
-					copy(o.BestOfBestInt, best.Int)
-				}
-			}
-
-			// check multi-objective results
-			if o.F1F0_func != nil {
-				var rms_err float64
-				var nfeasible int
-				for _, sol := range o.Solutions {
-					if sol.Feasible() && sol.FrontId == 0 {
-						f0, f1 := sol.Ova[0], sol.Ova[1]
-						f1_cor := o.F1F0_func(f0)
-						rms_err += math.Pow(f1-f1_cor, 2.0)
-						nfeasible++
-					}
-				}
-				if nfeasible > 0 {
-					rms_err = math.Sqrt(rms_err / float64(nfeasible))
-					o.F1F0_err = append(o.F1F0_err, rms_err)
-				}
-			}
-
-			// arc-length along Pareto front
-			if o.Nova == 2 {
-				if best.Feasible() && best.FrontId == 0 && o.Solutions[1].FrontId == 0 {
-					dist := 0.0
-					for i := 1; i < o.Nsol; i++ {
-						if o.Solutions[i].FrontId == 0 {
-							F0, F1 := o.Solutions[i-1].Ova[0], o.Solutions[i-1].Ova[1]
-							f0, f1 := o.Solutions[i].Ova[0], o.Solutions[i].Ova[1]
-							if o.F1F0_f0ranges != nil {
-								a := o.find_f0_spot(F0)
-								b := o.find_f0_spot(f0)
-								if a == -1 || b == -1 {
-									continue
-								}
-								if a != b {
-									//io.Pforan("\nF0=%g is in [%g,%g]\n", F0, o.F1F0_f0ranges[a][0], o.F1F0_f0ranges[a][1])
-									//io.Pfpink("f0=%g is in [%g,%g]\n", f0, o.F1F0_f0ranges[b][0], o.F1F0_f0ranges[b][1])
-									continue
-								}
-							}
-							dist += math.Sqrt(math.Pow(f0-F0, 2.0) + math.Pow(f1-F1, 2.0))
-						}
-					}
-					o.F1F0_arcLen = append(o.F1F0_arcLen, dist/denominatorL)
-				}
-			}
-
-			// multiple OVAs
-			if o.Nova > 1 && o.Multi_fcnErr != nil {
-				var rms_err float64
-				var nfeasible int
-				for _, sol := range o.Solutions {
-					if sol.Feasible() && sol.FrontId == 0 {
-						f_err := o.Multi_fcnErr(sol.Ova)
-						rms_err += f_err * f_err
-						nfeasible++
-					}
-				}
-				if nfeasible > 0 {
-					rms_err = math.Sqrt(rms_err / float64(nfeasible))
-					o.Multi_err = append(o.Multi_err, rms_err)
-				}
 			}
+		}
 
-			// IGD metric
-			if o.Nova > 1 && len(o.Multi_fStar) > 0 {
-				o.Multi_IGD = append(o.Multi_IGD, o.calcIgd(o.Multi_fStar))
-			}
+		// errors and metrics
+		o.F1F0_err = append(o.F1F0_err, res.f1f0Err...)
+		o.F1F0_arcLen = append(o.F1F0_arcLen, res.arcLen...)
+		o.Multi_err = append(o.Multi_err, res.multiErr...)
+		o.Multi_IGD = append(o.Multi_IGD, res.igd...)
+	}
 
-			// save final solutions
-			if fnkey != "" {
-				f0min := best.Ova[0]
-				for _, sol := range o.Solutions {
-					f0min = utl.Min(f0min, sol.Ova[0])
-				}
-				WriteAllValues(dirout, io.Sf("%s-%04d_f0min=%g", fnkey, itrial, f0min), o)
-			}
-		}
+	// statistics: convergence history
+	o.HistoryStat = nil
+	if o.RecHistory {
+		o.HistoryStat = newHistoryStat(o.Histories, o.Nova)
 	}
 
 	// statistics: F
@@ -301,6 +295,167 @@ func (o *Optimiser) RunMany(dirout, fnkey string, constantSeed bool) {
 	}
 }
 
+// trialSeeds returns the seeds of the trials of RunMany. The first trial runs on the current
+// solutions; thus its seed is not used to re-initialise the random numbers streams. The seeds do
+// not depend on the order in which the trials run
+func (o *Optimiser) trialSeeds(seed int, constantSeed bool) (seeds []int) {
+	seeds = make([]int, o.Nsamples)
+	rng := NewRnd(seed)
+	for itrial := range seeds {
+		switch {
+		case itrial == 0:
+			seeds[itrial] = seed
+		case o.SampleSeeds:
+			seeds[itrial] = seed + itrial
+		case constantSeed:
+			seeds[itrial] = seed
+		default:
+			seeds[itrial] = 1 + int(rng.Uint64()>>2)
+		}
+	}
+	return
+}
+
+// adoptTrial copies the state of the optimiser c into this optimiser; i.e. solutions, groups,
+// random numbers streams, restarts, archive and counters; e.g. the state of the clone that has run
+// the last trial of RunMany. The Cache is not copied
+func (o *Optimiser) adoptTrial(c *Optimiser) {
+	err := o.loadCheckpoint(c.newCheckpoint(), "of the last trial")
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	o.resume = false
+	o.Nhits, o.Nfailed = c.Nhits, c.Nfailed
+	o.StopReason, o.History = c.StopReason, c.History
+}
+
+// trialResult holds the results of one trial in RunMany
+type trialResult struct {
+	sysTime   time.Duration // system time
+	nrestarts int           // number of restarts
+	archive   []*Solution   // solutions in Archive
+	history   History       // convergence history
+	best      *Solution     // copy of best solution; nil if there are no feasible solutions
+	f1f0Err   []float64     // max(error(f1)) [0 or 1 value]
+	arcLen    []float64     // arc-length along Pareto front [0 or 1 value]
+	multiErr  []float64     // max(error(f[i])) [0 or 1 value]
+	igd       []float64     // IGD metric [0 or 1 value]
+}
+
+// runTrial solves the problem and collects the results of one trial of RunMany
+func (o *Optimiser) runTrial(itrial int, dirout, fnkey string, denominatorL float64) (res *trialResult) {
+	res = new(trialResult)
+
+	// save initial solutions
+	if fnkey != "" {
+		WriteAllValues(dirout, io.Sf("%s-%04d_ini", fnkey, itrial), o)
+	}
+
+	// message
+	if o.VerbStat {
+		io.Pf(". . . running trial # %d\n", itrial)
+	}
+
+	// solve
+	timeIni := time.Now()
+	o.Solve()
+	res.sysTime = time.Now().Sub(timeIni)
+	res.nrestarts = len(o.Restarts)
+	res.history = o.History
+	if o.Archive != nil {
+		res.archive = o.Archive.Solutions()
+	}
+
+	// sort
+	SortSolutions(o.Solutions, 0)
+
+	// feasible solution
+	if o.Solutions[0].Feasible() {
+
+		// best solution
+		best := o.Solutions[0]
+		res.best = NewSolution(best.Id, 0, &o.Parameters)
+		best.CopyInto(res.best)
+
+		// check multi-objective results
+		if o.F1F0_func != nil {
+			var rms_err float64
+			var nfeasible int
+			for _, sol := range o.Solutions {
+				if sol.Feasible() && sol.FrontId == 0 {
+					f0, f1 := sol.Ova[0], sol.Ova[1]
+					f1_cor := o.F1F0_func(f0)
+					rms_err += math.Pow(f1-f1_cor, 2.0)
+					nfeasible++
+				}
+			}
+			if nfeasible > 0 {
+				rms_err = math.Sqrt(rms_err / float64(nfeasible))
+				res.f1f0Err = append(res.f1f0Err, rms_err)
+			}
+		}
+
+		// arc-length along Pareto front
+		if o.Nova == 2 {
+			if best.Feasible() && best.FrontId == 0 && o.Solutions[1].FrontId == 0 {
+				dist := 0.0
+				for i := 1; i < o.Nsol; i++ {
+					if o.Solutions[i].FrontId == 0 {
+						F0, F1 := o.Solutions[i-1].Ova[0], o.Solutions[i-1].Ova[1]
+						f0, f1 := o.Solutions[i].Ova[0], o.Solutions[i].Ova[1]
+						if o.F1F0_f0ranges != nil {
+							a := o.find_f0_spot(F0)
+							b := o.find_f0_spot(f0)
+							if a == -1 || b == -1 {
+								continue
+							}
+							if a != b {
+								//io.Pforan("\nF0=%g is in [%g,%g]\n", F0, o.F1F0_f0ranges[a][0], o.F1F0_f0ranges[a][1])
+								//io.Pfpink("f0=%g is in [%g,%g]\n", f0, o.F1F0_f0ranges[b][0], o.F1F0_f0ranges[b][1])
+								continue
+							}
+						}
+						dist += math.Sqrt(math.Pow(f0-F0, 2.0) + math.Pow(f1-F1, 2.0))
+					}
+				}
+				res.arcLen = append(res.arcLen, dist/denominatorL)
+			}
+		}
+
+		// multiple OVAs
+		if o.Nova > 1 && o.Multi_fcnErr != nil {
+			var rms_err float64
+			var nfeasible int
+			for _, sol := range o.Solutions {
+				if sol.Feasible() && sol.FrontId == 0 {
+					f_err := o.Multi_fcnErr(sol.Ova)
+					rms_err += f_err * f_err
+					nfeasible++
+				}
+			}
+			if nfeasible > 0 {
+				rms_err = math.Sqrt(rms_err / float64(nfeasible))
+				res.multiErr = append(res.multiErr, rms_err)
+			}
+		}
+
+		// IGD metric
+		if o.Nova > 1 && len(o.Multi_fStar) > 0 {
+			res.igd = append(res.igd, o.calcIgd(o.Multi_fStar))
+		}
+
+		// save final solutions
+		if fnkey != "" {
+			f0min := best.Ova[0]
+			for _, sol := range o.Solutions {
+				f0min = utl.Min(f0min, sol.Ova[0])
+			}
+			WriteAllValues(dirout, io.Sf("%s-%04d_f0min=%g", fnkey, itrial, f0min), o)
+		}
+	}
+	return
+}
+
 // PrintStatF print statistical information corresponding to objective function idxF
 func (o *Optimiser) PrintStatF(idxF int) {
 	if len(o.BestOvas[idxF]) == 0 {
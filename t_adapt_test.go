@@ -0,0 +1,122 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_adapt01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("adapt01. success-history memories")
+
+	// weighted means
+	mem := newDEmemory(3)
+	mem.success(0.5, 0.2, -1)
+	mem.success(1.0, 0.6, 3)
+	mem.success(0, 0.9, 5) // ignored
+	mem.update()
+	chk.Array(tst, "F", 1e-15, mem.F, []float64{(0.25*0.25 + 0.75) / (0.25*0.5 + 0.75), 0.5, 0.5})
+	chk.Array(tst, "CR", 1e-15, mem.CR, []float64{0.25*0.2 + 0.75*0.6, 0.5, 0.5})
+	chk.Int(tst, "K", mem.K, 1)
+
+	// equal weights without improvement; and no update without successes
+	mem.success(0.2, 0.1, 0)
+	mem.success(0.4, 0.3, 0)
+	mem.update()
+	mem.update()
+	chk.Array(tst, "F", 1e-15, mem.F[1:], []float64{(0.04 + 0.16) / (0.2 + 0.4), 0.5})
+	chk.Array(tst, "CR", 1e-15, mem.CR[1:], []float64{0.2, 0.5})
+	chk.Int(tst, "K", mem.K, 2)
+
+	// random numbers from the stream of an optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Seed = 1234
+	opt.Verbose = false
+	opt.FltMin = []float64{0}
+	opt.FltMax = []float64{1}
+	opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {}, nil, 0, 0, 0)
+	rng := opt.Rnd
+	n := 20000
+	var sum, sum2 float64
+	nbelow := 0
+	for i := 0; i < n; i++ {
+		x := rng.Normal(1, 2)
+		sum += x
+		sum2 += x * x
+		if rng.Cauchy(3, 0.1) < 3 {
+			nbelow++
+		}
+	}
+	mean := sum / float64(n)
+	io.Pforan("normal: mean = %g  var = %g.  cauchy: P(x<x0) = %g\n", mean, sum2/float64(n)-mean*mean, float64(nbelow)/float64(n))
+	chk.Float64(tst, "mean", 0.05, mean, 1)
+	chk.Float64(tst, "variance", 0.1, sum2/float64(n)-mean*mean, 4)
+	chk.Float64(tst, "P(x<x0)", 0.02, float64(nbelow)/float64(n), 0.5)
+}
+
+func Test_adapt02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("adapt02. self-adaptive differential evolution")
+
+	for _, adapt := range []string{"jde", "shade"} {
+
+		// optimiser
+		opt := new(Optimiser)
+		opt.Default()
+		opt.Nsol = 40
+		opt.Ncpu = 2
+		opt.Tmax = 300
+		opt.DtExc = 30
+		opt.DEadapt = adapt
+		opt.DEstrategy = "current-to-pbest/1"
+		opt.RecHistory = true
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2, -2, -2}
+		opt.FltMax = []float64{2, 2, 2, 2}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = 0
+			for i := 0; i < 3; i++ {
+				f[0] += 100*(x[i+1]-x[i]*x[i])*(x[i+1]-x[i]*x[i]) + (1-x[i])*(1-x[i])
+			}
+		}, 1, 0, 0)
+		opt.Solve()
+
+		// adapted values. zero means that the solution has never been replaced by an offspring
+		nset := 0
+		for _, sol := range opt.Solutions {
+			if sol.DEF == 0 {
+				continue
+			}
+			nset++
+			if sol.DEF < 0 || sol.DEF > 1 || sol.DEC < 0 || sol.DEC > 1 {
+				tst.Errorf("%s: F = %g and CR = %g are incorrect\n", adapt, sol.DEF, sol.DEC)
+				return
+			}
+		}
+		if nset == 0 {
+			tst.Errorf("%s: F and CR should have been set\n", adapt)
+			return
+		}
+		for _, h := range opt.History {
+			io.Pf("%5s: t=%3d  F=%.3f  CR=%.3f  fbest=%g\n", adapt, h.Time, h.DEF, h.DEC, h.Fbest[0])
+			if h.DEF <= 0 || h.DEF > 1 || h.DEC < 0 || h.DEC > 1 {
+				tst.Errorf("%s: mean F = %g and CR = %g are incorrect\n", adapt, h.DEF, h.DEC)
+				return
+			}
+		}
+
+		// best solution
+		SortSolutions(opt.Solutions, 0)
+		io.Pforan("%5s: best = %v  f = %g\n", adapt, opt.Solutions[0].Flt, opt.Solutions[0].Ova[0])
+		chk.Array(tst, "best", 1e-2, opt.Solutions[0].Flt, []float64{1, 1, 1, 1})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_archive01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("archive01. add and prune")
+
+	// solutions
+	var prms Parameters
+	prms.Default()
+	prms.Nova = 2
+	prms.FltMin = []float64{0}
+	prms.FltMax = []float64{1}
+	prms.CalcDerived()
+	newsol := func(f0, f1 float64) (sol *Solution) {
+		sol = NewSolution(0, 0, &prms)
+		sol.Ova[0], sol.Ova[1] = f0, f1
+		return
+	}
+
+	// dominated and equal solutions are not added
+	arch := NewArchive(4, "hv", &prms)
+	add := func(sol *Solution, correct bool) {
+		if arch.Add(sol) != correct {
+			tst.Errorf("Add(%v) should have returned %v\n", sol.Ova, correct)
+		}
+	}
+	add(newsol(1, 1), true)
+	add(newsol(2, 2), false)
+	add(newsol(1, 1), false)
+	add(newsol(0, 3), true)
+	add(newsol(0.5, 0.5), true) // removes (1,1)
+	chk.Int(tst, "len", arch.Len(), 2)
+
+	// hypervolume contributions: (0.3,0.6) => 0.48, (0.5,0.5) => 0.05 and (1,0.4) => 0.2
+	arch.Add(newsol(3, 0))
+	arch.Add(newsol(0.3, 0.6))
+	arch.Add(newsol(1.0, 0.4))
+	chk.Int(tst, "len", arch.Len(), 4)
+	res := arch.Solutions()
+	f0 := make([]float64, len(res))
+	for i, sol := range res {
+		f0[i] = sol.Ova[0]
+	}
+	chk.Array(tst, "f0", 1e-15, f0, []float64{0, 0.3, 1, 3})
+
+	// infeasible solutions are not added
+	prms.Noor = 1
+	sol := newsol(-1, -1)
+	sol.Oor[0] = 1
+	add(sol, false)
+}
+
+func Test_archive02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("archive02. archive during Solve")
+
+	// optimiser: ZDT1 problem
+	newopt := func(archSize int) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 50
+		opt.Seed = 1234
+		opt.ArchSize = archSize
+		opt.Verbose = false
+		opt.FltMin = utl.Vals(5, 0)
+		opt.FltMax = utl.Vals(5, 1)
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			s := 0.0
+			for i := 1; i < len(x); i++ {
+				s += x[i]
+			}
+			c := 1.0 + 9.0*s/float64(len(x)-1)
+			f[0] = x[0]
+			f[1] = c * (1.0 - math.Sqrt(f[0]/c))
+		}, 2, 0, 0)
+		opt.Solve()
+		return
+	}
+
+	// check archives. a full archive may have discarded a solution that dominates solutions added
+	// later; thus, the final population is compared with the archive that never becomes full
+	for _, archSize := range []int{30, 100000} {
+		opt := newopt(archSize)
+		res := opt.Archive.Solutions()
+		io.Pforan("len(archive) = %d\n", len(res))
+		if len(res) < 2 || len(res) > opt.ArchSize {
+			tst.Errorf("number of solutions in archive is incorrect: %d\n", len(res))
+			return
+		}
+		for i, A := range res {
+			for j, B := range res {
+				if i != j {
+					A_dom, _ := A.Compare(B)
+					if A_dom {
+						tst.Errorf("solution %d in archive dominates solution %d\n", i, j)
+						return
+					}
+				}
+			}
+		}
+		if len(res) == opt.ArchSize {
+			continue
+		}
+
+		// no solution in final population dominates the archive
+		for _, sol := range opt.Solutions {
+			for _, a := range res {
+				sol_dom, _ := sol.Compare(a)
+				if sol_dom {
+					tst.Errorf("solution in population dominates solution in archive\n")
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_asktell01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("asktell01. ask and tell versus solve")
+
+	// problem
+	fcn := func(f, g, x []float64) {
+		f[0] = x[0]*x[0]/2.0 + x[1]*x[1] - x[0]*x[1] - 2.0*x[0] - 6.0*x[1]
+		g[0] = 2.0 - x[0] - x[1]
+	}
+
+	// optimiser
+	newopt := func() (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.DtExc = 10
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		return
+	}
+
+	// solve
+	optA := newopt()
+	optA.Nova, optA.Noor = 1, 1
+	optA.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+		g := make([]float64, 1)
+		fcn(sol.Ova, g, sol.Flt)
+		sol.Oor[0] = utl.GtePenalty(g[0], 0.0, 1)
+	}, nil, 0, 0, 0)
+	optA.Solve()
+
+	// ask and tell. evaluate copies to mimic external evaluation
+	optB := newopt()
+	optB.InitAskTell(GenTrialSolutions, 1, 1)
+	for {
+		sols := optB.Ask()
+		evaluated := make([]*Solution, len(sols))
+		for i, sol := range sols {
+			evaluated[i] = NewSolution(0, 0, &optB.Parameters)
+			sol.CopyInto(evaluated[i])
+			g := make([]float64, 1)
+			fcn(evaluated[i].Ova, g, evaluated[i].Flt)
+			evaluated[i].Oor[0] = utl.GtePenalty(g[0], 0.0, 1)
+		}
+		if optB.Tell(evaluated) {
+			break
+		}
+	}
+
+	// compare
+	io.Pforan("nfeval = %d and %d\n", optA.Nfeval, optB.Nfeval)
+	chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+	chk.String(tst, optB.StopReason, "tmax")
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+		chk.Array(tst, io.Sf("oor%d", i), 1e-17, optB.Solutions[i].Oor, sol.Oor)
+	}
+
+	// no more solutions after the run is done
+	if sols := optB.Ask(); sols != nil {
+		tst.Errorf("Ask must return nil after the run is done. %d solutions have been returned\n", len(sols))
+		return
+	}
+
+	// failed evaluation with FailPolicy == "abort"
+	optB.Reset(false)
+	sols := optB.Ask()
+	chk.Int(tst, "len(sols)", len(sols), optB.Nsol)
+	for _, sol := range sols {
+		sol.Ova[0], sol.Oor[0] = 0, 0
+	}
+	sols[3].Ova[0] = math.NaN()
+	done, err := optB.TellErr(sols)
+	io.Pforan("err = %v\n", err)
+	if !done || err == nil {
+		tst.Errorf("TellErr should have returned done and an error\n")
+		return
+	}
+	if sols := optB.Ask(); sols != nil {
+		tst.Errorf("Ask must return nil after a failed evaluation\n")
+		return
+	}
+}
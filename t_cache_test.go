@@ -0,0 +1,170 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_cache01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cache01. get, put and eviction")
+
+	// solutions
+	var prms Parameters
+	prms.Nova = 1
+	prms.Noor = 1
+	prms.Nflt = 2
+	prms.Nint = 1
+	sols := NewSolutions(3, &prms)
+	sols[0].Flt, sols[0].Int, sols[0].Ova[0] = []float64{1, 2}, []int{1}, 10
+	sols[1].Flt, sols[1].Int, sols[1].Ova[0] = []float64{1, 2}, []int{2}, 20
+	sols[2].Flt, sols[2].Int, sols[2].Ova[0] = []float64{3, 4}, []int{1}, 30
+
+	// put
+	cache := NewCache(2, 1e-3)
+	for _, sol := range sols[:2] {
+		cache.Put(sol)
+	}
+	chk.Int(tst, "len", cache.Len(), 2)
+
+	// get within tolerance
+	sol := NewSolution(0, 0, &prms)
+	sol.Flt, sol.Int = []float64{1.0001, 1.9999}, []int{1}
+	if !cache.Get(sol) {
+		tst.Errorf("solution should have been found in cache\n")
+		return
+	}
+	chk.Float64(tst, "ova", 1e-17, sol.Ova[0], 10)
+
+	// get outside tolerance
+	sol.Flt = []float64{1.01, 2}
+	if cache.Get(sol) {
+		tst.Errorf("solution should not have been found in cache\n")
+		return
+	}
+	chk.Int(tst, "nhits", cache.Hits(), 1)
+
+	// evict least recently used
+	cache.Put(sols[2])
+	chk.Int(tst, "len", cache.Len(), 2)
+	sol.Flt, sol.Int = []float64{1, 2}, []int{2}
+	if cache.Get(sol) {
+		tst.Errorf("least recently used solution should have been evicted\n")
+		return
+	}
+	sol.Flt, sol.Int = []float64{1, 2}, []int{1}
+	if !cache.Get(sol) {
+		tst.Errorf("recently used solution should not have been evicted\n")
+		return
+	}
+	chk.Int(tst, "nhits", cache.Hits(), 2)
+	cache.ResetHits()
+	chk.Int(tst, "nhits", cache.Hits(), 0)
+}
+
+func Test_cache02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cache02. optimiser with cache")
+
+	// optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 50
+	opt.Verbose = false
+	opt.BinInt = 6
+	opt.IntPm = 0.1
+	opt.CxInt = CxInt
+	opt.MtInt = MtIntBin
+	opt.CacheSize = 1000
+	var ncalls int64
+	opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+		atomic.AddInt64(&ncalls, 1)
+		sol.Ova[0] = 0
+		for _, y := range sol.Int {
+			sol.Ova[0] -= float64(y)
+		}
+	}, nil, 0, 0, 0)
+	opt.Solve()
+
+	// check
+	io.Pforan("ncalls = %d  nfeval = %d  nhits = %d\n", ncalls, opt.Nfeval, opt.Nhits)
+	chk.Int(tst, "nfeval", opt.Nfeval, int(ncalls))
+	chk.Int(tst, "nfeval+nhits", opt.Nfeval+opt.Nhits, opt.Nsol*(1+opt.Tmax))
+	if opt.Nhits == 0 {
+		tst.Errorf("cache should have been used\n")
+		return
+	}
+	if opt.Cache.Len() > 1<<uint(opt.BinInt) {
+		tst.Errorf("cache has too many entries: %d\n", opt.Cache.Len())
+	}
+}
+
+func Test_cache03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("cache03. large floats and duplicates in one batch")
+
+	// large floats: x/Tol does not fit in an int64
+	var prms Parameters
+	prms.Nova = 1
+	prms.Nflt = 1
+	cache := NewCache(10, 1e-13)
+	sols := NewSolutions(3, &prms)
+	sols[0].Flt[0], sols[0].Ova[0] = 1e6, 1
+	sols[1].Flt[0], sols[1].Ova[0] = 2e6, 2
+	sols[2].Flt[0], sols[2].Ova[0] = -1e6, 3
+	for _, sol := range sols {
+		cache.Put(sol)
+	}
+	chk.Int(tst, "len", cache.Len(), 3)
+	for _, sol := range sols {
+		s := NewSolution(0, 0, &prms)
+		s.Flt[0] = sol.Flt[0]
+		if !cache.Get(s) {
+			tst.Errorf("solution with x = %g should have been found in cache\n", s.Flt[0])
+			return
+		}
+		chk.Float64(tst, "ova", 1e-17, s.Ova[0], sol.Ova[0])
+	}
+
+	// optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 8
+	opt.Ncpu = 1
+	opt.Verbose = false
+	opt.FltMin = []float64{-1}
+	opt.FltMax = []float64{1}
+	opt.CacheSize = 100
+	var ncalls int64
+	opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+		atomic.AddInt64(&ncalls, 1)
+		sol.Ova[0] = sol.Flt[0] * sol.Flt[0]
+	}, nil, 0, 0, 0)
+
+	// duplicates in one batch are evaluated once
+	batch := NewSolutions(5, &opt.Parameters)
+	for i, x := range []float64{0.5, 0.2, 0.5, 0.5, 0.2} {
+		batch[i].Flt[0] = x
+	}
+	ncalls = 0
+	opt.Cache.ResetHits()
+	nfeval := opt.evaluate(batch, 0)
+	chk.Int(tst, "nfeval", nfeval, 2)
+	chk.Int(tst, "ncalls", int(ncalls), 2)
+	chk.Int(tst, "nhits", opt.Cache.Hits(), 3)
+	for _, sol := range batch {
+		chk.Float64(tst, "ova", 1e-17, sol.Ova[0], sol.Flt[0]*sol.Flt[0])
+	}
+}
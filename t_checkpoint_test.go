@@ -0,0 +1,254 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_checkpoint01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("checkpoint01. save and resume")
+
+	// optimiser
+	newopt := func() (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 1
+		opt.Tmax = 100
+		opt.DtExc = 10
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0]/2.0 + x[1]*x[1] - x[0]*x[1] - 2.0*x[0] - 6.0*x[1]
+			g[0] = 2.0 - x[0] - x[1]
+		}, 1, 1, 0)
+		return
+	}
+
+	// run and save checkpoint in the middle
+	os.MkdirAll("/tmp/goga", 0777)
+	path := "/tmp/goga/checkpoint01.json"
+	optA := newopt()
+	optA.Output = func(time int, sols []*Solution) {
+		if time == 50 {
+			err := optA.SaveCheckpoint(path)
+			if err != nil {
+				tst.Errorf("%v\n", err)
+			}
+		}
+	}
+	optA.Solve()
+
+	// resume
+	optB := newopt()
+	err := optB.LoadCheckpoint(path)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "time", optB.tcur, 50)
+	optB.Solve()
+
+	// compare
+	io.Pforan("nfeval = %d and %d\n", optA.Nfeval, optB.Nfeval)
+	chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+	}
+
+	// incompatible optimiser
+	optC := newopt()
+	optC.Nflt = 3
+	err = optC.LoadCheckpoint(path)
+	if err == nil {
+		tst.Errorf("LoadCheckpoint should have failed\n")
+	}
+}
+
+func Test_checkpoint02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("checkpoint02. self-adaptive differential evolution")
+
+	// optimiser
+	newopt := func(adapt string) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.DEadapt = adapt
+		opt.DEmem = 4
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.DtExc = 10
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0]/2.0 + x[1]*x[1] - x[0]*x[1] - 2.0*x[0] - 6.0*x[1]
+			g[0] = 2.0 - x[0] - x[1]
+		}, 1, 1, 0)
+		return
+	}
+
+	// run and save checkpoint in the middle; then resume
+	os.MkdirAll("/tmp/goga", 0777)
+	path := "/tmp/goga/checkpoint02.json"
+	for _, adapt := range []string{"jde", "shade"} {
+		var memF, memCR [][]float64
+		optA := newopt(adapt)
+		optA.Output = func(time int, sols []*Solution) {
+			if time == 50 {
+				err := optA.SaveCheckpoint(path)
+				if err != nil {
+					tst.Errorf("%v\n", err)
+				}
+				for _, grp := range optA.Groups {
+					if grp.deMem != nil {
+						memF = append(memF, append([]float64{}, grp.deMem.F...))
+						memCR = append(memCR, append([]float64{}, grp.deMem.CR...))
+					}
+				}
+			}
+		}
+		optA.Solve()
+
+		// resume
+		optB := newopt(adapt)
+		err := optB.LoadCheckpoint(path)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+
+		// memories
+		if adapt == "shade" {
+			chk.Int(tst, "number of memories", len(memF), len(optB.Groups))
+			for cpu, grp := range optB.Groups {
+				chk.Array(tst, io.Sf("F%d", cpu), 1e-17, grp.deMem.F, memF[cpu])
+				chk.Array(tst, io.Sf("CR%d", cpu), 1e-17, grp.deMem.CR, memCR[cpu])
+			}
+		}
+		optB.Solve()
+
+		// compare
+		io.Pforan("DEadapt = %q: nfeval = %d and %d\n", adapt, optA.Nfeval, optB.Nfeval)
+		chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+		for i, sol := range optA.Solutions {
+			chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+			chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+			chk.Array(tst, io.Sf("F and CR of %d", i), 1e-17, []float64{optB.Solutions[i].DEF, optB.Solutions[i].DEC}, []float64{sol.DEF, sol.DEC})
+		}
+	}
+
+	// checkpoint without memories
+	optC := newopt("")
+	optC.Output = func(time int, sols []*Solution) {
+		if time == 50 {
+			optC.SaveCheckpoint(path)
+		}
+	}
+	optC.Solve()
+	err := newopt("shade").LoadCheckpoint(path)
+	if err == nil {
+		tst.Errorf("LoadCheckpoint should have failed\n")
+	}
+}
+
+func Test_checkpoint03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("checkpoint03. restarts with growth of population and archive")
+
+	// optimiser: Rastrigin function
+	newopt := func() (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 8
+		opt.Ncpu = 2
+		opt.Tmax = 400
+		opt.DtExc = 5
+		opt.Seed = 1234
+		opt.RestartNexc = 2
+		opt.RestartGrow = 1.5
+		opt.RestartMax = 3
+		opt.ArchSize = 5
+		opt.Verbose = false
+		opt.FltMin = []float64{-5, -5}
+		opt.FltMax = []float64{5, 5}
+		opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+			x := sol.Flt
+			sol.Ova[0] = 20 + x[0]*x[0] + x[1]*x[1] - 10*(math.Cos(2*math.Pi*x[0])+math.Cos(2*math.Pi*x[1]))
+		}, nil, 0, 0, 0)
+		return
+	}
+
+	// run and save checkpoint after the second restart
+	os.MkdirAll("/tmp/goga", 0777)
+	path := "/tmp/goga/checkpoint03.json"
+	tsave := -1
+	optA := newopt()
+	optA.Output = func(time int, sols []*Solution) {
+		if tsave < 0 && len(optA.Restarts) == 2 {
+			tsave = time
+			err := optA.SaveCheckpoint(path)
+			if err != nil {
+				tst.Errorf("%v\n", err)
+			}
+		}
+	}
+	optA.Solve()
+	if tsave < 0 {
+		tst.Errorf("the second restart should have happened\n")
+		return
+	}
+
+	// resume
+	optB := newopt()
+	err := optB.LoadCheckpoint(path)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("tsave = %d  nsol = %d\n", tsave, optB.Nsol)
+	chk.Int(tst, "time", optB.tcur, tsave)
+	chk.Int(tst, "nrestarts", len(optB.Restarts), 2)
+	chk.Int(tst, "len(Solutions)", len(optB.Solutions), optB.Nsol)
+	if optB.Nsol == optB.nsol0 || optB.Best == nil || optB.Archive.Len() == 0 {
+		tst.Errorf("Nsol, Best and Archive should have been loaded\n")
+		return
+	}
+	optB.Solve()
+
+	// compare
+	io.Pforan("nfeval = %d and %d\n", optA.Nfeval, optB.Nfeval)
+	chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+	chk.Int(tst, "nsol", optB.Nsol, optA.Nsol)
+	chk.Int(tst, "nrestarts", len(optB.Restarts), len(optA.Restarts))
+	for i, r := range optA.Restarts {
+		chk.Ints(tst, io.Sf("restart%d", i), []int{optB.Restarts[i].Time, optB.Restarts[i].Nfeval, optB.Restarts[i].Nsol}, []int{r.Time, r.Nfeval, r.Nsol})
+	}
+	chk.Array(tst, "best", 1e-17, optB.Best.Flt, optA.Best.Flt)
+	archA, archB := optA.Archive.Solutions(), optB.Archive.Solutions()
+	chk.Int(tst, "len(Archive)", len(archB), len(archA))
+	for i, sol := range archA {
+		chk.Array(tst, io.Sf("archive%d", i), 1e-17, archB[i].Ova, sol.Ova) // solutions with equal Ova may be added in any order
+	}
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_fixed01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("fixed01. fixed and elite solutions")
+
+	// optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 50
+	opt.DtExc = 5
+	opt.Nelite = 2
+	opt.Verbose = false
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + x[1]*x[1]
+	}, 1, 0, 0)
+
+	// pin the worst possible solution
+	opt.SetFixed(3, []float64{2, 2}, nil)
+	chk.Float64(tst, "f(pinned)", 1e-15, opt.Solutions[3].Ova[0], 8)
+
+	// check pinned solution
+	check := func() {
+		sol := opt.Solutions[3]
+		if !sol.Fixed {
+			tst.Errorf("solution should be fixed\n")
+			return
+		}
+		chk.Array(tst, "x(pinned)", 1e-15, sol.Flt, []float64{2, 2})
+		chk.Float64(tst, "f(pinned)", 1e-15, sol.Ova[0], 8)
+	}
+
+	// solve and reset
+	opt.Solve()
+	check()
+	opt.Reset(false)
+	check()
+	opt.Solve()
+	check()
+
+	// check number of elite solutions
+	for cpu, grp := range opt.Groups {
+		nelite := 0
+		for _, sol := range grp.All[:grp.Ncur] {
+			if sol.Elite {
+				nelite++
+			}
+		}
+		io.Pforan("cpu %d: number of elite = %d\n", cpu, nelite)
+		chk.Int(tst, "nelite", nelite, opt.Nelite)
+	}
+
+	// protected solutions are not replaced in tournaments
+	A, B := NewSolution(0, 0, &opt.Parameters), NewSolution(1, 0, &opt.Parameters)
+	a, b := NewSolution(-1, 0, &opt.Parameters), NewSolution(-2, 0, &opt.Parameters)
+	A.Ova[0], B.Ova[0], a.Ova[0], b.Ova[0] = 3, 4, 1, 2
+	A.Elite, B.Fixed = true, true
+	opt.Tournament(A, B, a, b, opt.Metrics)
+	chk.Float64(tst, "f(A)", 1e-15, A.Ova[0], 3)
+	chk.Float64(tst, "f(B)", 1e-15, B.Ova[0], 4)
+	A.Elite, B.Fixed = false, false
+	opt.Tournament(A, B, a, b, opt.Metrics)
+	chk.Array(tst, "f(A),f(B)", 1e-15, []float64{A.Ova[0], B.Ova[0]}, []float64{2, 1})
+}
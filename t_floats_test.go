@@ -6,6 +6,7 @@ package goga
 
 import (
 	"math"
+	"sync/atomic"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
@@ -188,3 +189,395 @@ func Test_flt04(tst *testing.T) {
 		opt.PlotOvaOvaPareto(sols0, 0, 1, pp)
 	}
 }
+
+func Test_flt05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt05. user-defined crossover and mutation of floats")
+
+	// parameters
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 100
+	opt.Verbose = false
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	nf, ng, nh := 1, 0, 0
+
+	// arithmetic crossover and uniform mutation
+	var ncx, nmt int64
+	opt.CxFlt = func(a, b, A, B []float64, prms *Parameters, rng *Rnd) {
+		atomic.AddInt64(&ncx, 1)
+		α := rng.Float64(0, 1)
+		for i := 0; i < len(a); i++ {
+			a[i] = α*A[i] + (1-α)*B[i]
+			b[i] = (1-α)*A[i] + α*B[i]
+		}
+	}
+	opt.MtFlt = func(a []float64, prms *Parameters, rng *Rnd) {
+		atomic.AddInt64(&nmt, 1)
+		for i := 0; i < len(a); i++ {
+			if rng.FlipCoin(0.1) {
+				a[i] = rng.Float64(prms.FltMin[i], prms.FltMax[i])
+			}
+		}
+	}
+	opt.Islands = []*Island{{}, {CxFlt: func(a, b, A, B []float64, prms *Parameters, rng *Rnd) {
+		atomic.AddInt64(&ncx, 1)
+		copy(a, A)
+		copy(b, B)
+	}}}
+
+	// initialise optimiser
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = (x[0]-1)*(x[0]-1) + (x[1]+0.5)*(x[1]+0.5)
+	}, nf, ng, nh)
+
+	// solve
+	opt.Solve()
+	io.Pforan("ncx = %d  nmt = %d\n", ncx, nmt)
+	chk.Int(tst, "ncx", int(ncx), opt.Nsol/2*opt.Tmax)
+	chk.Int(tst, "nmt", int(nmt), opt.Nsol*opt.Tmax)
+	SortSolutions(opt.Solutions, 0)
+	best := opt.Solutions[0]
+	io.Pforan("best = %v  f = %g\n", best.Flt, best.Ova[0])
+	chk.Array(tst, "best", 0.1, best.Flt, []float64{1, -0.5})
+}
+
+func Test_flt06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt06. differential evolution strategies")
+
+	// parameters
+	var prms Parameters
+	prms.Default()
+	prms.FltMin = []float64{-1, -1, -1, -1}
+	prms.FltMax = []float64{1, 1, 1, 1}
+	prms.CalcDerived()
+	var rng Rnd
+	rng.Init(1234)
+
+	// best/1 with r1 == r2 and binomial crossover with C=1 gives the best vector
+	x := []float64{0, 0, 0, 0}
+	best := []float64{0.1, 0.2, 0.3, 0.4}
+	r := [][]float64{{0.5, 0.5, 0.5, 0.5}, {-0.5, 0.5, -0.5, 0.5}, {-0.5, 0.5, -0.5, 0.5}}
+	xnew := make([]float64, 4)
+	prms.DEstrategy, prms.DEC, prms.DEF = "best/1", 1, 0.5
+	DiffEvolStrategy(xnew, x, best, r, &prms, &rng)
+	chk.Array(tst, "best/1", 1e-15, xnew, best)
+
+	// current-to-best/1 moves x half-way towards best if F=0.5
+	prms.DEstrategy = "current-to-best/1"
+	DiffEvolStrategy(xnew, x, best, r, &prms, &rng)
+	chk.Array(tst, "current-to-best/1", 1e-15, xnew, []float64{0.05, 0.1, 0.15, 0.2})
+
+	// rand/2 is clamped into the range
+	prms.DEstrategy, prms.DEF = "rand/2", 1
+	DiffEvolStrategy(xnew, x, nil, [][]float64{{0.5, 0.5, 0.5, 0.5}, {1, 1, 1, 1}, {0, 0, 0, 0}, {1, 1, 1, 1}, {0, 0, 0, 0}}, &prms, &rng)
+	chk.Array(tst, "rand/2", 1e-15, xnew, []float64{1, 1, 1, 1})
+
+	// exponential crossover with C=0 changes one component only
+	prms.DEstrategy, prms.DEcross, prms.DEC = "rand/1", "exp", 0
+	DiffEvolStrategy(xnew, x, nil, r, &prms, &rng)
+	nchanged := 0
+	for i := 0; i < 4; i++ {
+		if xnew[i] != x[i] {
+			nchanged++
+			chk.Float64(tst, "changed", 1e-15, xnew[i], 0.5)
+		}
+	}
+	chk.Int(tst, "nchanged", nchanged, 1)
+
+	// solve with all strategies and crossovers
+	for _, strategy := range []string{"rand/1", "best/1", "current-to-best/1", "rand/2", "current-to-pbest/1"} {
+		for _, cross := range []string{"bin", "exp"} {
+			for _, F := range []float64{0, 0.5} {
+				var opt Optimiser
+				opt.Default()
+				opt.Nsol = 24
+				opt.Ncpu = 2
+				opt.Tmax = 200
+				opt.Verbose = false
+				opt.DEstrategy = strategy
+				opt.DEcross = cross
+				opt.DEF = F
+				if cross == "exp" {
+					opt.DEC = 0.9
+				}
+				opt.FltMin = []float64{-2, -2, -2}
+				opt.FltMax = []float64{2, 2, 2}
+				opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+					f[0] = x[0]*x[0] + (x[1]-1)*(x[1]-1) + (x[2]+1)*(x[2]+1)
+				}, 1, 0, 0)
+				opt.Solve()
+				SortSolutions(opt.Solutions, 0)
+				io.Pforan("%18s %s F=%g: f = %g\n", strategy, cross, F, opt.Solutions[0].Ova[0])
+				if opt.Solutions[0].Ova[0] > 1e-3 {
+					tst.Errorf("%s %s F=%g: best f = %g is too large\n", strategy, cross, F, opt.Solutions[0].Ova[0])
+				}
+			}
+		}
+	}
+
+	// invalid parameters
+	prms.Default()
+	prms.DEstrategy = "rand/3"
+	prms.DEcross = "uniform"
+	prms.DEp = 0
+	err := prms.Validate()
+	io.Pforan("%v\n", err)
+	if err == nil {
+		tst.Errorf("Validate should have failed\n")
+	}
+}
+
+func Test_flt07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt07. SBX crossover and polynomial mutation")
+
+	// parameters
+	for _, norm := range []bool{false, true} {
+		var prms Parameters
+		prms.Default()
+		prms.NormFlt = norm
+		prms.FltPc = 1
+		prms.FltPm = 1
+		prms.FltMin = []float64{-1, 0, 10}
+		prms.FltMax = []float64{1, 5, 11}
+		prms.CalcDerived()
+		var rng Rnd
+		rng.Init(1234)
+
+		// offspring are within range and, on average, centred at the mean of the parents
+		A := []float64{-0.5, 1, 10.2}
+		B := []float64{0.5, 4, 10.4}
+		a, b := make([]float64, 3), make([]float64, 3)
+		sum := make([]float64, 3)
+		n := 5000
+		for k := 0; k < n; k++ {
+			CxFltSBX(a, b, A, B, &prms, &rng)
+			for i := 0; i < 3; i++ {
+				if a[i] < prms.FltMin[i] || a[i] > prms.FltMax[i] || b[i] < prms.FltMin[i] || b[i] > prms.FltMax[i] {
+					tst.Errorf("SBX: offspring are out of range: a = %v, b = %v\n", a, b)
+					return
+				}
+				sum[i] += (a[i] + b[i]) / 2
+			}
+			MtFltPoly(a, &prms, &rng)
+			for i := 0; i < 3; i++ {
+				if a[i] < prms.FltMin[i] || a[i] > prms.FltMax[i] {
+					tst.Errorf("polynomial mutation: a = %v is out of range\n", a)
+					return
+				}
+			}
+		}
+		for i := 0; i < 3; i++ {
+			chk.Float64(tst, "SBX: mean", 0.05*(prms.FltMax[i]-prms.FltMin[i]), sum[i]/float64(n), (A[i]+B[i])/2)
+		}
+
+		// no crossover
+		prms.FltPc = 0
+		CxFltSBX(a, b, A, B, &prms, &rng)
+		chk.Array(tst, "SBX: a", 1e-15, a, A)
+		chk.Array(tst, "SBX: b", 1e-15, b, B)
+	}
+
+	// solve
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 40
+	opt.Ncpu = 2
+	opt.Tmax = 200
+	opt.Verbose = false
+	opt.FltOp = "sbx"
+	opt.FltMin = []float64{-2, -2, -2}
+	opt.FltMax = []float64{2, 2, 2}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + (x[1]-1)*(x[1]-1) + (x[2]+1)*(x[2]+1)
+	}, 1, 0, 0)
+	opt.Solve()
+	SortSolutions(opt.Solutions, 0)
+	io.Pforan("best = %v  f = %g\n", opt.Solutions[0].Flt, opt.Solutions[0].Ova[0])
+	chk.Array(tst, "best", 0.05, opt.Solutions[0].Flt, []float64{0, 1, -1})
+}
+
+func Test_flt08(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt08. boundary handling of floats")
+
+	// parameters
+	var prms Parameters
+	prms.Default()
+	prms.FltMin = []float64{0}
+	prms.FltMax = []float64{10}
+	prms.CalcDerived()
+	var rng Rnd
+	rng.Init(1234)
+
+	// bound floats. EnforceRange takes the middle of the range as parent
+	x := []float64{-3, 13, 25, -23, 4}
+	res := map[string][]float64{
+		"clamp":    {0, 10, 10, 0, 4},
+		"reflect":  {3, 7, 5, 3, 4},
+		"wrap":     {7, 3, 5, 7, 4},
+		"random":   {0, 10, 10, 0, 4},
+		"midpoint": {2.5, 7.5, 7.5, 2.5, 4},
+		"resample": {0, 10, 10, 0, 4},
+	}
+	for _, bound := range []string{"clamp", "reflect", "wrap", "random", "midpoint", "resample"} {
+		prms.FltBound = bound
+		y := make([]float64, len(x))
+		z := make([]float64, len(x))
+		for k := range x {
+			y[k] = prms.BoundFlt(0, x[k], 5, nil)
+			z[k] = prms.EnforceRange(0, x[k])
+		}
+		chk.Array(tst, "BoundFlt: "+bound, 1e-15, y, res[bound])
+		chk.Array(tst, "EnforceRange: "+bound, 1e-15, z, res[bound])
+	}
+
+	// parent and random numbers
+	prms.FltBound = "midpoint"
+	chk.Float64(tst, "midpoint", 1e-15, prms.BoundFlt(0, 13, 8, &rng), 9)
+	chk.Float64(tst, "midpoint", 1e-15, prms.BoundFlt(0, -1, 8, &rng), 4)
+	prms.FltBound = "random"
+	for k := 0; k < 100; k++ {
+		v := prms.BoundFlt(0, 13, 8, &rng)
+		if v < 0 || v > 10 || v == 10 {
+			tst.Errorf("random: v = %g is not a random value within range\n", v)
+			return
+		}
+	}
+
+	// solve problem with optimum on the bounds
+	for _, bound := range []string{"clamp", "reflect", "wrap", "random", "midpoint", "resample"} {
+		var opt Optimiser
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltBound = bound
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = (x[0]-2)*(x[0]-2) + x[1]*x[1]
+		}, 1, 0, 0)
+		opt.Solve()
+		for _, sol := range opt.Solutions {
+			if !opt.fltInRange(sol.Flt) {
+				tst.Errorf("%s: x = %v is out of range\n", bound, sol.Flt)
+				return
+			}
+		}
+		SortSolutions(opt.Solutions, 0)
+		io.Pforan("%-8s: best = %v  f = %g\n", bound, opt.Solutions[0].Flt, opt.Solutions[0].Ova[0])
+		chk.Array(tst, "best: "+bound, 0.05, opt.Solutions[0].Flt, []float64{2, 0})
+	}
+}
+
+func Test_flt09(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt09. SBX crossover and polynomial mutation with zero range")
+
+	// pinned float: FltMin[1] == FltMax[1]
+	for _, norm := range []bool{false, true} {
+		var prms Parameters
+		prms.Default()
+		prms.NormFlt = norm
+		prms.FltPc = 1
+		prms.FltPm = 1
+		prms.FltMin = []float64{-1, 3}
+		prms.FltMax = []float64{1, 3}
+		prms.CalcDerived()
+		rng := NewRnd(1234)
+		A := []float64{-0.5, 3}
+		B := []float64{0.5, 3}
+		a, b := make([]float64, 2), make([]float64, 2)
+		for k := 0; k < 100; k++ {
+			CxFltSBX(a, b, A, B, &prms, rng)
+			MtFltPoly(a, &prms, rng)
+			MtFltPoly(b, &prms, rng)
+			if math.IsNaN(a[0]) || math.IsNaN(b[0]) {
+				tst.Errorf("offspring must not be NaN: a = %v, b = %v\n", a, b)
+				return
+			}
+			chk.Float64(tst, "a1", 1e-15, a[1], 3)
+			chk.Float64(tst, "b1", 1e-15, b[1], 3)
+		}
+	}
+
+	// solve
+	for _, op := range []string{"de", "sbx"} {
+		var opt Optimiser
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 50
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltOp = op
+		opt.NormFlt = true
+		opt.FltMin = []float64{-2, 3}
+		opt.FltMax = []float64{2, 3}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0] + x[1]
+		}, 1, 0, 0)
+		opt.Solve()
+		for _, sol := range opt.Solutions {
+			if math.IsNaN(sol.Flt[0]) || math.IsNaN(sol.Ova[0]) {
+				tst.Errorf("%s: solution must not be NaN: x = %v\n", op, sol.Flt)
+				return
+			}
+			chk.Float64(tst, op+": x1", 1e-15, sol.Flt[1], 3)
+		}
+	}
+}
+
+func Test_flt10(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("flt10. boundary handling of user-defined crossover")
+
+	// crossover overshooting the bounds: 11 → 9 and -2 → 2 after reflection
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 10
+	opt.Seed = 1234
+	opt.Verbose = false
+	opt.FltBound = "reflect"
+	opt.FltMin = []float64{0, 0}
+	opt.FltMax = []float64{10, 10}
+	opt.CxFlt = func(a, b, A, B []float64, prms *Parameters, rng *Rnd) {
+		for i := 0; i < len(a); i++ {
+			a[i] = prms.FltMax[i] + 1
+			b[i] = prms.FltMin[i] - 2
+		}
+	}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = (x[0]-9)*(x[0]-9) + (x[1]-9)*(x[1]-9)
+	}, 1, 0, 0)
+	opt.Solve()
+
+	// check
+	for _, sol := range opt.Solutions {
+		for _, x := range sol.Flt {
+			if x == 0 || x == 10 {
+				tst.Errorf("x = %v must not be clamped\n", sol.Flt)
+				return
+			}
+		}
+	}
+	SortSolutions(opt.Solutions, 0)
+	io.Pforan("best = %v\n", opt.Solutions[0].Flt)
+	chk.Array(tst, "best", 1e-15, opt.Solutions[0].Flt, []float64{9, 9})
+}
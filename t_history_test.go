@@ -0,0 +1,160 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_history01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("history01. hypervolume and quartiles")
+
+	chk.Float64(tst, "hv: 2D", 1e-15, hypervolume([][]float64{{1, 3}, {2, 2}, {3, 1}, {3, 3}, {5, 0}}, []float64{4, 4}), 6)
+	chk.Float64(tst, "hv: 3D", 1e-15, hypervolume([][]float64{{0, 0, 0}}, []float64{1, 2, 3}), 6)
+	chk.Float64(tst, "hv: 3D", 1e-15, hypervolume([][]float64{{0, 1, 1}, {1, 0, 1}, {1, 1, 0}}, []float64{2, 2, 2}), 4)
+	chk.Float64(tst, "hv: none", 1e-15, hypervolume(nil, []float64{1, 1}), 0)
+
+	q1, median, q3 := quartiles([]float64{5, 1, 4, 2, 3})
+	chk.Array(tst, "quartiles: odd", 1e-15, []float64{q1, median, q3}, []float64{2, 3, 4})
+	q1, median, q3 = quartiles([]float64{4, 3, 2, 1})
+	chk.Array(tst, "quartiles: even", 1e-15, []float64{q1, median, q3}, []float64{1.75, 2.5, 3.25})
+	q1, median, q3 = quartiles([]float64{7})
+	chk.Array(tst, "quartiles: one", 1e-15, []float64{q1, median, q3}, []float64{7, 7, 7})
+}
+
+func Test_history02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("history02. convergence history")
+
+	// optimiser
+	opt := new(Optimiser)
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 40
+	opt.DtExc = 5
+	opt.Nsamples = 3
+	opt.RecHistory = true
+	opt.Verbose = false
+	opt.FltMin = []float64{0, 0}
+	opt.FltMax = []float64{1, 1}
+	opt.Multi_fStar = [][]float64{{0, 1}, {0.25, 0.5}, {1, 0}}
+	opt.Multi_hvRef = []float64{1.1, 1.1}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]
+		f[1] = (1 + x[1]) * (1 - math.Sqrt(x[0]/(1+x[1])))
+		g[0] = x[0] + x[1] - 0.1
+	}, 2, 1, 0)
+
+	// solve
+	opt.Solve()
+	chk.Int(tst, "len(History)", len(opt.History), opt.Tmax/opt.DtExc)
+	for k, h := range opt.History {
+		io.Pforan("t=%3d nfeval=%4d fbest=%v feasible=%g nfront0=%d igd=%g hv=%g\n", h.Time, h.Nfeval, h.Fbest, h.Feasible, h.Nfront0, h.IGD, h.HV)
+		chk.Int(tst, "time", h.Time, (k+1)*opt.DtExc)
+		if h.Feasible < 0 || h.Feasible > 1 || h.IGD == INF || h.HV == INF {
+			tst.Errorf("feasible fraction, IGD or HV are incorrect\n")
+			return
+		}
+		if k > 0 && h.Nfeval <= opt.History[k-1].Nfeval {
+			tst.Errorf("number of function evaluations must increase\n")
+			return
+		}
+		for i := 0; i < opt.Nova; i++ {
+			if h.Fbest[i] > h.Fmean[i] || h.Fmean[i] > h.Fworst[i] {
+				tst.Errorf("statistics of objective values are incorrect\n")
+				return
+			}
+		}
+	}
+
+	// export
+	dir, err := ioutil.TempDir("", "goga")
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	readLines := func(path string) (lines []string, err error) {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return
+		}
+		return strings.Split(strings.TrimSpace(string(b)), "\n"), nil
+	}
+	err = opt.History.WriteCSV(filepath.Join(dir, "hist.csv"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	lines, err := readLines(filepath.Join(dir, "hist.csv"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "CSV: number of lines", len(lines), 1+len(opt.History))
+	chk.String(tst, lines[0], "time,nfeval,elapsed,fbest0,fbest1,fmean0,fmean1,fworst0,fworst1,feasible,nfront0,igd,hv,def,dec")
+	err = opt.History.WriteJSON(filepath.Join(dir, "hist.json"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "hist.json"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	var hist History
+	err = json.Unmarshal(b, &hist)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "JSON: len(History)", len(hist), len(opt.History))
+	chk.Array(tst, "JSON: Fbest", 1e-15, hist[2].Fbest, opt.History[2].Fbest)
+
+	// run many
+	opt.RunMany("", "", false)
+	chk.Int(tst, "len(Histories)", len(opt.Histories), opt.Nsamples)
+	stat := opt.HistoryStat
+	chk.Int(tst, "len(HistoryStat.Time)", len(stat.Time), opt.Tmax/opt.DtExc)
+	for k := range stat.Time {
+		chk.Int(tst, "Ntrials", stat.Ntrials[k], opt.Nsamples)
+		for _, c := range []*HistoryCurve{stat.Nfeval, stat.Fbest[0], stat.Fbest[1], stat.IGD, stat.HV} {
+			if c.Q1[k] > c.Median[k] || c.Median[k] > c.Q3[k] {
+				tst.Errorf("quartiles are incorrect\n")
+				return
+			}
+		}
+	}
+	err = stat.WriteCSV(filepath.Join(dir, "stat.csv"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	lines, err = readLines(filepath.Join(dir, "stat.csv"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "CSV: number of lines", len(lines), 1+len(stat.Time))
+	err = stat.WriteJSON(filepath.Join(dir, "stat.json"))
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_island01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("island01. heterogeneous islands")
+
+	// optimiser
+	newopt := func(islands []*Island) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 50
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.IntMin = []int{0}
+		opt.IntMax = []int{9}
+		opt.CxInt = CxInt
+		opt.MtInt = MtInt
+		opt.Islands = islands
+		return
+	}
+	obj := func(sol *Solution, cpu int) {
+		x, y := sol.Flt, sol.Int
+		sol.Ova[0] = x[0]*x[0] + x[1]*x[1] + float64(y[0])
+	}
+
+	// exploratory island with 8 solutions and exploitative island with 12 solutions
+	fp := func(v float64) *float64 { return &v }
+	var nmut [2]int
+	opt := newopt([]*Island{
+		{Nsol: 8, DEC: fp(0.9), MtIntRng: func(a []int, prms *Parameters, rng *Rnd) {
+			nmut[0]++
+			MtIntRng(a, prms, rng)
+		}},
+		{Nsol: 12, DEC: fp(0.1), IntPm: fp(0.5), MtIntRng: func(a []int, prms *Parameters, rng *Rnd) {
+			nmut[1]++
+			MtIntRng(a, prms, rng)
+		}},
+	})
+	opt.Init(GenTrialSolutions, obj, nil, 0, 0, 0)
+	chk.Int(tst, "Ncur0", opt.Groups[0].Ncur, 8)
+	chk.Int(tst, "Ncur1", opt.Groups[1].Ncur, 12)
+	chk.Float64(tst, "DEC0", 1e-15, opt.Groups[0].Prms.DEC, 0.9)
+	chk.Float64(tst, "DEC1", 1e-15, opt.Groups[1].Prms.DEC, 0.1)
+	chk.Float64(tst, "IntPm0", 1e-15, opt.Groups[0].Prms.IntPm, opt.IntPm)
+	chk.Float64(tst, "IntPm1", 1e-15, opt.Groups[1].Prms.IntPm, 0.5)
+	opt.Solve()
+	chk.Ints(tst, "nmut", nmut[:], []int{8 * opt.Tmax, 12 * opt.Tmax})
+
+	// statistics
+	stats := opt.IslandStats()
+	chk.Int(tst, "len(stats)", len(stats), 2)
+	for cpu, s := range stats {
+		io.Pforan("island %d: %+v\n", cpu, *s)
+		chk.Int(tst, "Nsol", s.Nsol, opt.Groups[cpu].Ncur)
+		chk.Int(tst, "Noffspring", s.Noffspring, s.Nsol*opt.Tmax)
+		if s.Nsuccess < 1 || s.Nsuccess > s.Noffspring {
+			tst.Errorf("number of successful offspring is incorrect: %d\n", s.Nsuccess)
+			return
+		}
+	}
+	if chk.Verbose {
+		opt.PrintStatIslands()
+	}
+
+	// differential evolution and SBX. zero values may be set
+	opt = newopt([]*Island{
+		{FltOp: "de", DEF: fp(0.3), DEC: fp(0), DEstrategy: "best/1"},
+		{FltOp: "sbx", FltPm: fp(0.2), IntPm: fp(0)},
+	})
+	opt.DEF = 0.7
+	opt.Init(GenTrialSolutions, obj, nil, 0, 0, 0)
+	p0, p1 := opt.Groups[0].Prms, opt.Groups[1].Prms
+	chk.String(tst, p0.FltOp, "de")
+	chk.String(tst, p1.FltOp, "sbx")
+	chk.String(tst, p0.DEstrategy, "best/1")
+	chk.String(tst, p1.DEstrategy, opt.DEstrategy)
+	chk.Float64(tst, "DEF0", 1e-15, p0.DEF, 0.3)
+	chk.Float64(tst, "DEF1", 1e-15, p1.DEF, 0.7)
+	chk.Float64(tst, "DEC0", 1e-15, p0.DEC, 0)
+	chk.Float64(tst, "DEC1", 1e-15, p1.DEC, opt.DEC)
+	chk.Float64(tst, "FltPm0", 1e-15, p0.FltPm, opt.FltPm)
+	chk.Float64(tst, "FltPm1", 1e-15, p1.FltPm, 0.2)
+	chk.Float64(tst, "IntPm0", 1e-15, p0.IntPm, opt.IntPm)
+	chk.Float64(tst, "IntPm1", 1e-15, p1.IntPm, 0)
+	if cx, _ := opt.groupFltOperators(0, p0); cx != nil {
+		tst.Errorf("island 0 must use differential evolution\n")
+		return
+	}
+	if cx, mt := opt.groupFltOperators(1, p1); cx == nil || mt == nil {
+		tst.Errorf("island 1 must use SBX and polynomial mutation\n")
+		return
+	}
+	opt.Solve()
+	chk.Int(tst, "Ncur0", opt.Groups[0].Ncur, 10)
+	chk.Int(tst, "Ncur1", opt.Groups[1].Ncur, 10)
+
+	// invalid islands
+	for _, islands := range [][]*Island{
+		{{Nsol: 8}},
+		{{Nsol: 8}, {}},
+		{{Nsol: 8}, {Nsol: 8}},
+		{{DEstrategy: "rand/3"}, {}},
+		{{}, {FltOp: "pso"}},
+	} {
+		opt = newopt(islands)
+		err := opt.InitErr(GenTrialSolutions, obj, nil, 0, 0, 0)
+		io.Pforan("err = %v\n", err)
+		if err == nil {
+			tst.Errorf("InitErr should have returned an error\n")
+			return
+		}
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_mesh01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mesh01. random points around vertices")
+
+	// square with centre point
+	X := []float64{0, 1, 1, 0, 0.5}
+	Y := []float64{0, 0, 1, 1, 0.5}
+	m := NewMesh(X, Y)
+	io.Pforan("C = %v\n", m.C)
+	chk.Int(tst, "ncells", len(m.C), 4)
+	chk.Int(tst, "ncells(centre)", len(m.Vcells[4]), 4)
+	area := 0.0
+	for _, a := range m.Area {
+		area += a
+	}
+	chk.Float64(tst, "area", 1e-15, area, 1)
+
+	// points around corner must be in the cells sharing the corner
+	rng := NewRnd(1234)
+	for k := 0; k < 100; k++ {
+		x, y, ok := m.RandomPointAround(0, rng)
+		if !ok {
+			tst.Errorf("point should have been generated\n")
+			return
+		}
+		if x < 0 || y < 0 || x > 1 || y > 1 || (x > 0.5 && y > 0.5) {
+			tst.Errorf("point (%g,%g) is not in the cells around vertex 0\n", x, y)
+			return
+		}
+	}
+
+	// invalid vertex
+	if _, _, ok := m.RandomPointAround(5, rng); ok {
+		tst.Errorf("point should not have been generated\n")
+	}
+}
+
+func Test_mesh02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("mesh02. mesh-guided offspring movement")
+
+	// optimiser
+	newopt := func(pm float64) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.DtExc = 10
+		opt.Seed = 1234
+		opt.UseMesh = true
+		opt.MeshPm = pm
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2, -2}
+		opt.FltMax = []float64{2, 2, 2}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0]/2.0 + x[1]*x[1] - x[0]*x[1] - 2.0*x[0] - 6.0*x[1] + x[2]*x[2]
+			g[0] = 2.0 - x[0] - x[1]     // ≥ 0
+			g[1] = 2.0 + x[0] - 2.0*x[1] // ≥ 0
+			g[2] = 3.0 - 2.0*x[0] - x[1] // ≥ 0
+			g[3] = x[0]                  // ≥ 0
+			g[4] = x[1]                  // ≥ 0
+		}, 1, 5, 0)
+		return
+	}
+
+	// meshes are built for all pairs
+	opt := newopt(0.5)
+	chk.Int(tst, "Nsol", opt.Nsol, 20+opt.NumExtraSols)
+	for i := 0; i < opt.Nflt-1; i++ {
+		for j := i + 1; j < opt.Nflt; j++ {
+			if opt.Meshes[i][j] == nil || len(opt.Meshes[i][j].C) == 0 {
+				tst.Errorf("mesh (%d,%d) has not been built\n", i, j)
+				return
+			}
+		}
+	}
+
+	// solve with and without mesh movement
+	sols0 := opt.GetSolutionsCopy()
+	opt.Solve()
+	ref := newopt(0)
+	ref.Solve()
+
+	// boundary solutions are fixed
+	for k := opt.Nsol - opt.NumExtraSols; k < opt.Nsol; k++ {
+		if !opt.Solutions[k].Fixed {
+			tst.Errorf("boundary solution %d should be fixed\n", k)
+			return
+		}
+		chk.Array(tst, io.Sf("x%d", k), 1e-15, opt.Solutions[k].Flt, sols0[k].Flt)
+	}
+
+	// the optimum is still found
+	best, _ := GetBestFeasible(opt, 0)
+	bref, _ := GetBestFeasible(ref, 0)
+	io.Pforan("best (mesh)    = %v  x = %v\n", best.Ova, best.Flt)
+	io.Pforan("best (no mesh) = %v  x = %v\n", bref.Ova, bref.Flt)
+	chk.Float64(tst, "fmin", 1e-2, best.Ova[0], -8.2222)
+
+	// tells whether (x[i],x[j]) is in one of the cells around the parent, for some (i,j) pair
+	inCell := func(m *Mesh, c int, x, y float64) bool {
+		a, b, d := m.V[m.C[c][0]], m.V[m.C[c][1]], m.V[m.C[c][2]]
+		det := (b[0]-a[0])*(d[1]-a[1]) - (d[0]-a[0])*(b[1]-a[1])
+		s := ((x-a[0])*(d[1]-a[1]) - (d[0]-a[0])*(y-a[1])) / det
+		t := ((b[0]-a[0])*(y-a[1]) - (x-a[0])*(b[1]-a[1])) / det
+		return s >= -1e-10 && t >= -1e-10 && s+t <= 1+1e-10
+	}
+	aroundParent := func(opt *Optimiser, parent, sol *Solution) (around, meshed bool) {
+		v := opt.solIndex[parent]
+		meshed = true
+		for i := 0; i < opt.Nflt-1; i++ {
+			for j := i + 1; j < opt.Nflt; j++ {
+				m := opt.Meshes[i][j]
+				meshed = meshed && len(m.Vcells[v]) > 0
+				for _, c := range m.Vcells[v] {
+					if inCell(m, c, sol.Flt[i], sol.Flt[j]) {
+						around = true
+					}
+				}
+			}
+		}
+		return
+	}
+
+	// offspring are placed in the cells around their parents with MeshPm = 1; but not without mesh
+	// movement (MeshPm = 0). Boundary solutions coinciding with others in some (i,j) plane do not
+	// belong to any cell; thus, their offspring may not be moved
+	for _, pm := range []float64{1, 0} {
+		for _, seed := range []int{1234, 4321, 1111} {
+			o := newopt(pm)
+			o.Seed = seed
+			o.Reset(true)
+			nout, nmeshed := 0, 0
+			for cpu, grp := range o.Groups {
+				o.genOffspring(cpu)
+				z := grp.Ncur
+				for _, pair := range grp.Pairs {
+					for _, k := range pair {
+						around, meshed := aroundParent(o, grp.All[k], grp.All[z+k])
+						if meshed {
+							nmeshed++
+							if !around {
+								nout++
+							}
+						}
+					}
+				}
+			}
+			io.Pforan("MeshPm = %g  seed = %d  offspring away from their parents = %d of %d\n", pm, seed, nout, nmeshed)
+			if nmeshed < o.Nsol-o.NumExtraSols {
+				tst.Errorf("the parents of at least %d offspring should belong to cells\n", o.Nsol-o.NumExtraSols)
+				return
+			}
+			if pm == 1 && nout > 0 {
+				tst.Errorf("all offspring should be in the cells around their parents. %d are not\n", nout)
+				return
+			}
+			if pm == 0 && nout == 0 {
+				tst.Errorf("some offspring should not be in the cells around their parents without mesh movement\n")
+				return
+			}
+		}
+	}
+}
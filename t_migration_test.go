@@ -0,0 +1,123 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_migration01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("migration01. topologies and policies")
+
+	// optimiser
+	obj := func(sol *Solution, cpu int) {
+		sol.Ova[0] = sol.Flt[0] * sol.Flt[0]
+	}
+	newopt := func(m Migration, nsol, ncpu int) (opt *Optimiser, err error) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = nsol
+		opt.Ncpu = ncpu
+		opt.Tmax = 20
+		opt.Verbose = false
+		opt.FltMin = []float64{-1}
+		opt.FltMax = []float64{1}
+		opt.Migration = m
+		err = opt.InitErr(GenTrialSolutions, obj, nil, 0, 0, 0)
+		return
+	}
+
+	// topologies; with the stream of an optimiser
+	ref, err := newopt(nil, 8, 4)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	for _, c := range []struct {
+		topology string
+		nlinks   int
+	}{{"ring", 4}, {"full", 12}, {"star", 6}} {
+		m := &Migrator{Topology: c.topology}
+		links := m.links(4, ref.Rnd)
+		io.Pforan("%6s: %v\n", c.topology, links)
+		chk.Int(tst, c.topology, len(links), c.nlinks)
+	}
+	links := (&Migrator{Topology: "random", Prob: 1}).links(4, ref.Rnd)
+	chk.Int(tst, "random with Prob=1", len(links), 12)
+
+	// invalid data
+	for _, m := range []*Migrator{
+		{Topology: "tree", Select: "best", Replace: "worst"},
+		{Topology: "ring", Select: "worst", Replace: "worst"},
+		{Topology: "ring", Select: "best", Replace: "best"},
+		{Topology: "ring", Select: "best", Replace: "worst", Prob: 2},
+	} {
+		_, err := newopt(m, 6, 2)
+		if err == nil {
+			tst.Errorf("InitErr should have failed with %+v\n", *m)
+			return
+		}
+		io.Pforan("%v\n", err)
+	}
+
+	// best migrants replace worst solutions in ring
+	m := &Migrator{Topology: "ring", Select: "best", Replace: "worst", Rate: 2, Interval: 2}
+	opt, err := newopt(m, 6, 2)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	for cpu, grp := range opt.Groups {
+		for i, sol := range grp.All[:grp.Ncur] {
+			sol.Flt[0] = float64(10*cpu + i)
+			obj(sol, cpu)
+		}
+	}
+	opt.Metrics.Compute(opt.Solutions)
+	opt.iova0 = 0
+	migrated := opt.exchange()
+	chk.Int(tst, "migrated (interval)", len(migrated), 0)
+	opt.iova0 = 1
+	migrated = opt.exchange()
+	chk.Int(tst, "migrated", len(migrated), 4)
+	x := make([][]float64, 2)
+	for cpu, grp := range opt.Groups {
+		for _, sol := range grp.All[:grp.Ncur] {
+			x[cpu] = append(x[cpu], sol.Flt[0])
+		}
+	}
+	io.Pforan("x = %v\n", x)
+	chk.Array(tst, "x0", 1e-15, x[0], []float64{0, 11, 10})
+	chk.Array(tst, "x1", 1e-15, x[1], []float64{10, 1, 0})
+
+	// solve with all topologies and policies
+	for _, topology := range []string{"ring", "full", "star", "random"} {
+		for _, sel := range []string{"best", "random", "front0"} {
+			for _, rep := range []string{"worst", "random", "tournament"} {
+				opt, err = newopt(&Migrator{Topology: topology, Select: sel, Replace: rep}, 24, 4)
+				if err != nil {
+					tst.Errorf("%v\n", err)
+					return
+				}
+				opt.Solve()
+				best := opt.Solutions[0].Ova[0]
+				for _, sol := range opt.Solutions {
+					if sol.Ova[0] < best {
+						best = sol.Ova[0]
+					}
+				}
+				if best > 1e-2 {
+					tst.Errorf("%s/%s/%s: best = %g is too large\n", topology, sel, rep, best)
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// recorder records events
+type recorder struct {
+	NopObserver
+	ngen     []int     // [cpu] number of GenerationDone events
+	nexc     int       // number of ExchangeDone events
+	nfront   int       // number of Front0Changed events
+	best     []float64 // ova[0] of best solutions
+	finished []string  // stop reasons
+}
+
+func (o *recorder) GenerationDone(e *Event) { o.ngen[e.Group]++ }
+func (o *recorder) ExchangeDone(e *Event)   { o.nexc++ }
+func (o *recorder) Front0Changed(e *Event)  { o.nfront++ }
+func (o *recorder) NewBest(e *Event)        { o.best = append(o.best, e.Best.Ova[0]) }
+func (o *recorder) RunFinished(e *Event)    { o.finished = append(o.finished, e.StopReason) }
+
+func Test_observer01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("observer01. events")
+
+	// optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 100
+	opt.DtExc = 10
+	opt.Verbose = false
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	rec := &recorder{ngen: make([]int, 2)}
+	opt.Observers = []Observer{rec}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + x[1]*x[1]
+		g[0] = x[0] + 1.0
+	}, 1, 1, 0)
+	opt.Solve()
+
+	// check
+	io.Pforan("best = %v\n", rec.best)
+	chk.Ints(tst, "ngen", rec.ngen, []int{opt.Tmax, opt.Tmax})
+	chk.Int(tst, "nexc", rec.nexc, opt.Tmax/opt.DtExc)
+	chk.Int(tst, "nfront", rec.nfront, 0)
+	chk.Strings(tst, "finished", rec.finished, []string{"tmax"})
+	if len(rec.best) == 0 {
+		tst.Errorf("NewBest should have been called\n")
+		return
+	}
+	for i := 1; i < len(rec.best); i++ {
+		if rec.best[i] >= rec.best[i-1] {
+			tst.Errorf("best values must decrease: %v\n", rec.best)
+			return
+		}
+	}
+	best, _ := GetBestFeasible(&opt, 0)
+	chk.Float64(tst, "best", 1e-15, rec.best[len(rec.best)-1], best.Ova[0])
+}
+
+func Test_observer02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("observer02. front 0 changes and ask-tell")
+
+	// optimiser
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 50
+	opt.DtExc = 10
+	opt.Verbose = false
+	opt.FltMin = []float64{0, 0}
+	opt.FltMax = []float64{1, 1}
+	rec := &recorder{ngen: make([]int, 2)}
+	opt.Observers = []Observer{rec}
+	opt.InitAskTell(GenTrialSolutions, 2, 0)
+	for {
+		sols := opt.Ask()
+		for _, sol := range sols {
+			sol.Ova[0] = sol.Flt[0]
+			sol.Ova[1] = 1.0 - sol.Flt[0] + sol.Flt[1]
+		}
+		if opt.Tell(sols) {
+			break
+		}
+	}
+
+	// check
+	io.Pforan("nfront = %d\n", rec.nfront)
+	chk.Ints(tst, "ngen", rec.ngen, []int{opt.Tmax, opt.Tmax})
+	chk.Int(tst, "nexc", rec.nexc, opt.Tmax/opt.DtExc)
+	chk.Strings(tst, "finished", rec.finished, []string{"tmax"})
+	if rec.nfront == 0 {
+		tst.Errorf("Front0Changed should have been called\n")
+	}
+}
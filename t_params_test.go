@@ -0,0 +1,122 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_params01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("params01. validate")
+
+	// valid parameters
+	var prms Parameters
+	prms.Default()
+	prms.FltMin = []float64{-1, -1}
+	prms.FltMax = []float64{1, 1}
+	err := prms.Validate()
+	if err != nil {
+		tst.Errorf("parameters should be valid:\n%v\n", err)
+		return
+	}
+
+	// many problems
+	prms.Nsol = 4
+	prms.Ncpu = 3
+	prms.FltMax = []float64{1}
+	prms.FailPolicy = "ignore"
+	prms.FailNresample = -1
+	err = prms.Validate()
+	io.Pforan("%v\n", err)
+	errs, ok := err.(ParamErrors)
+	if !ok {
+		tst.Errorf("error should be of type ParamErrors\n")
+		return
+	}
+	fields := make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	chk.Strings(tst, "fields", fields, []string{"Nsol", "Ncpu", "FltMax", "FailPolicy", "FailNresample"})
+
+	// inverted ranges
+	var inv Parameters
+	inv.Default()
+	inv.FltMin = []float64{-1, 1, 0}
+	inv.FltMax = []float64{1, -1, 0}
+	inv.IntMin = []int{5}
+	inv.IntMax = []int{2}
+	err = inv.Validate()
+	io.Pforan("%v\n", err)
+	errs, ok = err.(ParamErrors)
+	if !ok {
+		tst.Errorf("error should be of type ParamErrors\n")
+		return
+	}
+	fields = make([]string, len(errs))
+	for i, e := range errs {
+		fields[i] = e.Field
+	}
+	chk.Strings(tst, "fields", fields, []string{"FltMax", "IntMax"})
+
+	// Init must not panic
+	var opt Optimiser
+	opt.Parameters = prms
+	err = opt.InitErr(GenTrialSolutions, func(sol *Solution, cpu int) {}, nil, 0, 0, 0)
+	if _, ok := err.(ParamErrors); !ok {
+		tst.Errorf("InitErr should have returned ParamErrors. err = %v\n", err)
+		return
+	}
+
+	// missing objective function
+	opt.Default()
+	opt.FltMin = []float64{-1, -1}
+	opt.FltMax = []float64{1, 1}
+	err = opt.InitErr(GenTrialSolutions, nil, nil, 0, 0, 0)
+	if err == nil {
+		tst.Errorf("InitErr should have failed because there is no objective function\n")
+	}
+}
+
+func Test_params02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("params02. read")
+
+	// missing file
+	var prms Parameters
+	err := prms.ReadErr("/tmp/goga/params02-missing.json")
+	if err == nil {
+		tst.Errorf("ReadErr should have failed\n")
+		return
+	}
+
+	// invalid file
+	os.MkdirAll("/tmp/goga", 0777)
+	ioutil.WriteFile("/tmp/goga/params02-invalid.json", []byte(`{"Nsol": "many"}`), 0644)
+	err = prms.ReadErr("/tmp/goga/params02-invalid.json")
+	io.Pforan("%v\n", err)
+	if err == nil {
+		tst.Errorf("ReadErr should have failed\n")
+		return
+	}
+
+	// valid file
+	ioutil.WriteFile("/tmp/goga/params02-valid.json", []byte(`{"Nsol": 60, "FltMin": [-1], "FltMax": [1]}`), 0644)
+	err = prms.ReadErr("/tmp/goga/params02-valid.json")
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "Nsol", prms.Nsol, 60)
+	chk.Int(tst, "Ncpu", prms.Ncpu, 4)
+}
@@ -0,0 +1,56 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_random01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("random01. streams of random numbers")
+
+	// integers
+	rng := NewRnd(1234)
+	n, m := 3, 30000
+	count := make([]int, n)
+	for i := 0; i < m; i++ {
+		count[rng.Intn(n)]++
+	}
+	io.Pforan("count = %v\n", count)
+	for k := 0; k < n; k++ {
+		chk.Float64(tst, io.Sf("P(%d)", k), 0.02, float64(count[k])/float64(m), 1.0/float64(n))
+	}
+
+	// latin hypercube: each dimension must be a permutation of 1..npts
+	dim, npts := 3, 10
+	x := rng.LatinIHS(dim, npts, 5)
+	io.Pforan("x = %v\n", x)
+	for i := 0; i < dim; i++ {
+		levels := utl.IntCopy(x[i])
+		sort.Ints(levels)
+		chk.Ints(tst, io.Sf("levels%d", i), levels, utl.IntRange2(1, npts+1))
+	}
+
+	// the functions without stream use the default stream
+	A := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	B := []int{-1, -2, -3, -4, -5, -6, -7, -8}
+	a, b := make([]int, 8), make([]int, 8)
+	var prms Parameters
+	prms.Default()
+	prms.IntPc, prms.IntNcuts = 1, 2
+	InitDefaultRnd(1234)
+	CxInt(a, b, A, B, &prms)
+	c, d := make([]int, 8), make([]int, 8)
+	CxIntRng(c, d, A, B, &prms, NewRnd(1234))
+	chk.Ints(tst, "a", a, c)
+	chk.Ints(tst, "b", b, d)
+}
@@ -0,0 +1,71 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_restart01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("restart01. restarts with growth of population")
+
+	// optimiser: Rastrigin function
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 8
+	opt.Ncpu = 2
+	opt.Tmax = 400
+	opt.DtExc = 5
+	opt.Seed = 1234
+	opt.RestartNexc = 2
+	opt.RestartGrow = 1.5
+	opt.RestartMax = 3
+	opt.Verbose = false
+	opt.FltMin = []float64{-5, -5}
+	opt.FltMax = []float64{5, 5}
+	var ncalls int64
+	opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+		x := sol.Flt
+		sol.Ova[0] = 20 + x[0]*x[0] + x[1]*x[1] - 10*(math.Cos(2*math.Pi*x[0])+math.Cos(2*math.Pi*x[1]))
+		atomic.AddInt64(&ncalls, 1)
+	}, nil, 0, 0, 0)
+
+	// solve
+	opt.Solve()
+	io.Pforan("nrestarts = %d  nsol = %d  nfeval = %d\n", len(opt.Restarts), opt.Nsol, opt.Nfeval)
+	chk.Int(tst, "nrestarts", len(opt.Restarts), 3)
+	chk.Int(tst, "nfeval", opt.Nfeval, int(ncalls))
+	nsol := 8
+	for i, r := range opt.Restarts {
+		io.Pforan("restart %d: %+v\n", i, *r)
+		chk.Int(tst, "nsol", r.Nsol, nsol)
+		nsol = int(math.Ceil(float64(nsol)*1.5/2.0)) * 2
+	}
+	chk.Int(tst, "nsol", opt.Nsol, nsol)
+	chk.Int(tst, "len(Solutions)", len(opt.Solutions), nsol)
+
+	// the best solution is kept
+	best, _ := GetBestFeasible(&opt, 0)
+	if best.Ova[0] > opt.Best.Ova[0] {
+		tst.Errorf("best solution found before restarts should have been restored\n")
+		return
+	}
+
+	// reset recovers the initial number of solutions
+	opt.Reset(false)
+	chk.Int(tst, "nsol", opt.Nsol, 8)
+	chk.Int(tst, "len(Solutions)", len(opt.Solutions), 8)
+	if opt.Best != nil || len(opt.Restarts) != 0 {
+		tst.Errorf("Reset should have cleared Best and Restarts\n")
+		return
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"runtime"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_runmany01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("runmany01. samples run at the same time")
+
+	// optimiser. the objective function uses one buffer per cpu index; thus, it fails if two
+	// trials running at the same time use the same cpu index
+	newopt := func(nova, npll int, sampleSeeds bool) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 50
+		opt.Seed = 1234
+		opt.Nsamples = 7
+		opt.NsamplesPll = npll
+		opt.SampleSeeds = sampleSeeds
+		opt.Verbose = false
+		opt.FltMin = []float64{0, 0}
+		opt.FltMax = []float64{1, 1}
+		opt.IntMin = []int{0}
+		opt.IntMax = []int{9}
+		opt.CxInt = CxInt
+		opt.MtInt = MtInt
+		opt.Terminators = []Terminator{&TermStagnation{Nexc: 3, Tol: 1e-15}}
+		if nova > 1 {
+			opt.Multi_fStar = [][]float64{{0, 1}, {0.25, 0.5}, {1, 0}}
+			opt.F1F0_func = func(f0 float64) float64 { return 1 - math.Sqrt(f0) }
+		}
+		busy := make([]int32, npll*opt.Ncpu)
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			if !atomic.CompareAndSwapInt32(&busy[cpu], 0, 1) {
+				tst.Errorf("cpu index %d is being used by another trial\n", cpu)
+			}
+			runtime.Gosched()
+			f[0] = math.Pow(x[0]-0.3, 2) + math.Pow(x[1]-0.6, 2) + float64(y[0])
+			if nova > 1 {
+				f[0] = x[0]
+				f[1] = (1 + x[1]) * (1 - math.Sqrt(x[0]/(1+x[1])))
+			}
+			atomic.StoreInt32(&busy[cpu], 0)
+		}, nova, 0, 0)
+		return
+	}
+
+	// compare sequential and concurrent runs with seeds for each sample, a constant seed or seeds
+	// drawn from a stream
+	for _, c := range []struct{ sampleSeeds, constantSeed bool }{{true, false}, {false, true}, {false, false}} {
+		sampleSeeds, constantSeed := c.sampleSeeds, c.constantSeed
+		for _, nova := range []int{1, 2} {
+			optA, optB := newopt(nova, 1, sampleSeeds), newopt(nova, 3, sampleSeeds)
+			optA.RunMany("", "", constantSeed)
+			optB.RunMany("", "", constantSeed)
+			io.Pforan("sampleSeeds = %v  constantSeed = %v  nova = %d  BestOvas[0] = %v\n", sampleSeeds, constantSeed, nova, optB.BestOvas[0])
+			chk.Int(tst, "len(SysTimes)", len(optB.SysTimes), optA.Nsamples)
+			for i, dur := range optB.SysTimes {
+				if dur <= 0 || optA.SysTimes[i] <= 0 {
+					tst.Errorf("system time of trial %d should be positive\n", i)
+					return
+				}
+			}
+			chk.Int(tst, "seed", optB.Seed, 1234)
+			for i := 0; i < nova; i++ {
+				chk.Array(tst, io.Sf("BestOvas[%d]", i), 1e-17, optB.BestOvas[i], optA.BestOvas[i])
+			}
+			for i := 0; i < optA.Nflt; i++ {
+				chk.Array(tst, io.Sf("BestFlts[%d]", i), 1e-17, optB.BestFlts[i], optA.BestFlts[i])
+			}
+			for i := 0; i < optA.Nint; i++ {
+				chk.Ints(tst, io.Sf("BestInts[%d]", i), optB.BestInts[i], optA.BestInts[i])
+			}
+			chk.Array(tst, "F1F0_err", 1e-17, optB.F1F0_err, optA.F1F0_err)
+			chk.Array(tst, "Multi_IGD", 1e-17, optB.Multi_IGD, optA.Multi_IGD)
+			if nova > 1 && (len(optB.Multi_IGD) != optA.Nsamples || len(optB.F1F0_err) != optA.Nsamples) {
+				tst.Errorf("IGD and errors should have been computed for all trials\n")
+				return
+			}
+
+			// state of the optimiser after the last trial
+			chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+			chk.String(tst, optB.StopReason, optA.StopReason)
+			chk.Int(tst, "len(Solutions)", len(optB.Solutions), len(optA.Solutions))
+			for i, sol := range optA.Solutions {
+				chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+				chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+				chk.Int(tst, io.Sf("front%d", i), optB.Solutions[i].FrontId, sol.FrontId)
+			}
+		}
+	}
+}
+
+func Test_runmany02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("runmany02. first trial runs on the solutions generated by Init")
+
+	// optimiser
+	newopt := func(nsamples, npll int, ncalls *int32) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 20
+		opt.Seed = 1234
+		opt.Nsamples = nsamples
+		opt.NsamplesPll = npll
+		opt.Verbose = false
+		opt.FltMin = []float64{0, 0}
+		opt.FltMax = []float64{1, 1}
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			atomic.AddInt32(ncalls, 1)
+			f[0] = math.Pow(x[0]-0.3, 2) + math.Pow(x[1]-0.6, 2)
+		}, 1, 0, 0)
+		return
+	}
+
+	// reference: Solve after Init
+	var nref int32
+	ref := newopt(1, 1, &nref)
+	ref.Solve()
+	SortSolutions(ref.Solutions, 0)
+
+	// one trial; sequential and concurrent. no extra evaluations
+	for _, npll := range []int{1, 2} {
+		var ncalls int32
+		opt := newopt(1, npll, &ncalls)
+		opt.RunMany("", "", false)
+		io.Pforan("npll = %d  ncalls = %d  nref = %d\n", npll, ncalls, nref)
+		chk.Int(tst, "ncalls", int(ncalls), int(nref))
+		for i, sol := range ref.Solutions {
+			chk.Array(tst, io.Sf("flt%d", i), 1e-17, opt.Solutions[i].Flt, sol.Flt)
+		}
+	}
+
+	// the solutions changed after Init are used by the first trial
+	for _, npll := range []int{1, 2} {
+		var ncalls int32
+		opt := newopt(2, npll, &ncalls)
+		for _, sol := range opt.Solutions {
+			sol.Flt[0], sol.Flt[1] = 0.3, 0.6
+			sol.Ova[0] = 0
+		}
+		opt.RunMany("", "", false)
+		chk.Float64(tst, io.Sf("npll = %d: BestOvas[0][0]", npll), 1e-17, opt.BestOvas[0][0], 0)
+		if opt.BestOvas[0][1] == 0 {
+			tst.Errorf("npll = %d: the solutions of the second trial should have been re-generated\n", npll)
+			return
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_seeds01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("seeds01. seeded initial solutions")
+
+	// optimiser
+	newopt := func(seedFlt [][]float64, seedInt [][]int) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 10
+		opt.Ncpu = 2
+		opt.Tmax = 20
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.IntMin = []int{0}
+		opt.IntMax = []int{9}
+		opt.CxInt = CxInt
+		opt.MtInt = MtInt
+		opt.SeedFlt = seedFlt
+		opt.SeedInt = seedInt
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0] + x[1]*x[1] + float64(y[0])
+		}, 1, 0, 0)
+		return
+	}
+
+	// seeds are distributed among groups; out-of-range values are clamped
+	opt := newopt([][]float64{{0, 0}, {1, 1}, {5, -5}}, [][]int{{0}, {1}, {20}})
+	chk.Array(tst, "x0", 1e-15, opt.Solutions[0].Flt, []float64{0, 0})
+	chk.Array(tst, "x5", 1e-15, opt.Solutions[5].Flt, []float64{1, 1})
+	chk.Array(tst, "x1", 1e-15, opt.Solutions[1].Flt, []float64{2, -2})
+	chk.Ints(tst, "y0", opt.Solutions[0].Int, []int{0})
+	chk.Ints(tst, "y5", opt.Solutions[5].Int, []int{1})
+	chk.Ints(tst, "y1", opt.Solutions[1].Int, []int{9})
+	chk.Float64(tst, "f0", 1e-15, opt.Solutions[0].Ova[0], 0)
+	chk.Float64(tst, "f5", 1e-15, opt.Solutions[5].Ova[0], 3)
+	chk.Float64(tst, "f1", 1e-15, opt.Solutions[1].Ova[0], 17)
+
+	// seeds are placed again on reset
+	opt.Solve()
+	opt.Reset(false)
+	chk.Array(tst, "x0", 1e-15, opt.Solutions[0].Flt, []float64{0, 0})
+
+	// invalid seeds
+	opt = new(Optimiser)
+	opt.Default()
+	opt.Nsol = 10
+	opt.Ncpu = 2
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	opt.SeedFlt = [][]float64{{0, 0, 0}}
+	err := opt.InitErr(GenTrialSolutions, func(sol *Solution, cpu int) {}, nil, 0, 0, 0)
+	io.Pforan("err = %v\n", err)
+	if err == nil {
+		tst.Errorf("InitErr should have returned an error\n")
+		return
+	}
+
+	// read back seeds from results file
+	opt = newopt(nil, nil)
+	opt.Solve()
+	WriteAllValues("/tmp/goga", "test_seeds01", opt)
+	flts, ints, err := ReadSeeds("/tmp/goga/test_seeds01.res")
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "nflts", len(flts), opt.Nsol)
+	chk.Int(tst, "nints", len(ints), opt.Nsol)
+	for i, sol := range opt.Solutions {
+		chk.Array(tst, io.Sf("x%d", i), 1e-5, flts[i], sol.Flt)
+		chk.Ints(tst, io.Sf("y%d", i), ints[i], sol.Int)
+	}
+	_, _, err = ReadSeeds("/tmp/goga/doesnotexist.res")
+	if err == nil {
+		tst.Errorf("ReadSeeds should have returned an error\n")
+		return
+	}
+}
+
+func Test_seeds02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("seeds02. seeds and boundary solutions of meshes")
+
+	// optimiser with 10 interior and 4 boundary solutions; 12 seeds
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 10
+	opt.Ncpu = 2
+	opt.Tmax = 10
+	opt.Verbose = false
+	opt.UseMesh = true
+	opt.Nbry = 2
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	for k := 0; k < 12; k++ {
+		opt.SeedFlt = append(opt.SeedFlt, []float64{0.1 * float64(k), -0.1 * float64(k)})
+	}
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + x[1]*x[1]
+	}, 1, 0, 0)
+	chk.Int(tst, "Nsol", opt.Nsol, 14)
+
+	// boundary solutions are kept
+	corners := [][]float64{{-2, -2}, {-2, 2}, {2, 2}, {2, -2}}
+	for i, x := range corners {
+		sol := opt.Solutions[10+i]
+		chk.Array(tst, io.Sf("x%d", 10+i), 1e-15, sol.Flt, x)
+		if !sol.Fixed {
+			tst.Errorf("boundary solution %d must be Fixed\n", 10+i)
+			return
+		}
+	}
+
+	// the interior solutions are seeds; the last two seeds are ignored
+	used := make(map[int]bool)
+	for i := 0; i < 10; i++ {
+		k := int(math.Round(opt.Solutions[i].Flt[0] * 10))
+		chk.Array(tst, io.Sf("x%d", i), 1e-15, opt.Solutions[i].Flt, opt.SeedFlt[k])
+		used[k] = true
+	}
+	chk.Int(tst, "number of seeds used", len(used), 10)
+	if used[10] || used[11] {
+		tst.Errorf("the last two seeds should have been ignored\n")
+		return
+	}
+}
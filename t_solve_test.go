@@ -0,0 +1,440 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_solve01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve01. cancel SolveContext")
+
+	// parameters
+	var opt Optimiser
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Tmax = 1000
+	opt.Verbose = false
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	nf, ng, nh := 1, 0, 0
+
+	// initialise optimiser; cancel after some evaluations
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var ncalls int64
+	opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + x[1]*x[1]
+		if atomic.AddInt64(&ncalls, 1) == 200 {
+			cancel()
+		}
+	}, nf, ng, nh)
+
+	// solve
+	err := opt.SolveContext(ctx)
+	io.Pforan("err = %v\n", err)
+	io.Pforan("nfeval = %v\n", opt.Nfeval)
+	if err != context.Canceled {
+		tst.Errorf("SolveContext should have returned context.Canceled. err = %v\n", err)
+		return
+	}
+	if opt.Nfeval >= opt.Tmax*opt.Nsol {
+		tst.Errorf("SolveContext should have stopped earlier. nfeval = %d\n", opt.Nfeval)
+		return
+	}
+
+	// metrics must be consistent with solutions
+	for _, sol := range opt.Solutions {
+		if sol.Ova[0] < opt.Metrics.Omin[0] || sol.Ova[0] > opt.Metrics.Omax[0] {
+			tst.Errorf("metrics are not consistent with solutions\n")
+			return
+		}
+	}
+}
+
+func Test_solve02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve02. termination criteria")
+
+	// optimiser
+	newopt := func(terms ...Terminator) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 1000
+		opt.DtExc = 10
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.Terminators = terms
+		opt.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0] = x[0]*x[0] + x[1]*x[1]
+		}, 1, 0, 0)
+		return
+	}
+
+	// no criteria
+	opt := newopt()
+	opt.Solve()
+	chk.String(tst, opt.StopReason, "tmax")
+
+	// max nfeval
+	opt = newopt(&TermNfeval{Max: 1000})
+	opt.Solve()
+	io.Pforan("nfeval = %v\n", opt.Nfeval)
+	chk.String(tst, opt.StopReason, "nfeval")
+	if opt.Nfeval < 1000 || opt.Nfeval > 1000+opt.DtExc*opt.Nsol {
+		tst.Errorf("nfeval = %d is incorrect\n", opt.Nfeval)
+		return
+	}
+
+	// target
+	opt = newopt(&TermTarget{Fref: []float64{0}, Tol: 1e-3})
+	opt.Solve()
+	chk.String(tst, opt.StopReason, "target")
+	best, _ := GetBestFeasible(opt, 0)
+	io.Pforan("best = %v\n", best.Ova)
+	if best.Ova[0] > 1e-3 {
+		tst.Errorf("target has not been reached\n")
+		return
+	}
+
+	// stagnation or target, whichever comes first
+	opt = newopt(&TermStagnation{Nexc: 3, Tol: 1e-15}, &TermTarget{Fref: []float64{-1}})
+	opt.Solve()
+	chk.String(tst, opt.StopReason, "stagnation")
+
+	// all criteria
+	opt = newopt(&TermAll{List: []Terminator{&TermNfeval{Max: 2000}, &TermStagnation{Nexc: 2, Tol: 1e-15}}})
+	opt.Solve()
+	chk.String(tst, opt.StopReason, "nfeval+stagnation")
+	if opt.Nfeval < 2000 {
+		tst.Errorf("nfeval = %d is incorrect\n", opt.Nfeval)
+		return
+	}
+}
+
+func Test_solve03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve03. reproducibility with random numbers streams")
+
+	// optimiser
+	newopt := func() (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 40
+		opt.Ncpu = 4
+		opt.Tmax = 100
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.IntMin = []int{0, 0}
+		opt.IntMax = []int{9, 9}
+		opt.CxInt = CxInt
+		opt.MtInt = MtInt
+		opt.Init(GenTrialSolutions, func(sol *Solution, cpu int) {
+			x, y := sol.Flt, sol.Int
+			sol.Ova[0] = x[0]*x[0] + x[1]*x[1] + float64(y[0]*y[1])
+		}, nil, 0, 0, 0)
+		return
+	}
+
+	// run two optimisers at the same time
+	optA, optB := newopt(), newopt()
+	done := make(chan int, 2)
+	for _, opt := range []*Optimiser{optA, optB} {
+		go func(opt *Optimiser) {
+			opt.Solve()
+			done <- 1
+		}(opt)
+	}
+	<-done
+	<-done
+
+	// compare
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Ints(tst, io.Sf("int%d", i), optB.Solutions[i].Int, sol.Int)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+	}
+}
+
+func Test_solve04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve04. batch objective function")
+
+	// optimiser
+	newopt := func() (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.Nova = 1
+		return
+	}
+	obj := func(sol *Solution, cpu int) {
+		x := sol.Flt
+		sol.Ova[0] = x[0]*x[0] + x[1]*x[1]
+	}
+
+	// one by one
+	optA := newopt()
+	optA.Init(GenTrialSolutions, obj, nil, 0, 0, 0)
+	optA.Solve()
+
+	// all offspring of a group at once
+	ncalls := make([]int, 2)
+	optB := newopt()
+	optB.BatchObjFunc = func(sols []*Solution, cpu int) {
+		ncalls[cpu]++
+		if len(sols) != optB.Nsol/optB.Ncpu {
+			tst.Errorf("batch has %d solutions; but %d were expected\n", len(sols), optB.Nsol/optB.Ncpu)
+		}
+		for _, sol := range sols {
+			obj(sol, cpu)
+		}
+	}
+	optB.Init(GenTrialSolutions, nil, nil, 0, 0, 0)
+	optB.Solve()
+
+	// compare
+	io.Pforan("ncalls = %v\n", ncalls)
+	chk.Ints(tst, "ncalls", ncalls, []int{1 + optB.Tmax, 1 + optB.Tmax})
+	chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+	}
+}
+
+func Test_solve05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve05. failed evaluations")
+
+	// optimiser. evaluations fail if x[0] > 1
+	newopt := func(policy string, withMinProb bool) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Tmax = 100
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		opt.FailPolicy = policy
+		if withMinProb {
+			opt.MinProbErr = func(f, g, h, x []float64, y []int, cpu int) error {
+				f[0] = x[0]*x[0] + x[1]*x[1]
+				if x[0] > 1 {
+					f[0] = math.NaN()
+				}
+				return nil
+			}
+			opt.Init(GenTrialSolutions, nil, nil, 1, 0, 0)
+			return
+		}
+		opt.Nova = 1
+		opt.ObjFuncErr = func(sol *Solution, cpu int) error {
+			x := sol.Flt
+			if x[0] > 1 {
+				return chk.Err("simulation did not converge")
+			}
+			sol.Ova[0] = x[0]*x[0] + x[1]*x[1]
+			return nil
+		}
+		opt.Init(GenTrialSolutions, nil, nil, 0, 0, 0)
+		return
+	}
+
+	// mark as infeasible
+	for _, withMinProb := range []bool{false, true} {
+		opt := newopt("infeasible", withMinProb)
+		opt.Solve()
+		io.Pforan("nfailed = %d\n", opt.Nfailed)
+		chk.String(tst, opt.StopReason, "tmax")
+		if opt.Nfailed == 0 {
+			tst.Errorf("some evaluations should have failed\n")
+			return
+		}
+		best, _ := GetBestFeasible(opt, 0)
+		chk.Float64(tst, "best", 1e-2, best.Ova[0], 0)
+	}
+
+	// re-sample
+	opt := newopt("resample", false)
+	opt.Solve()
+	io.Pforan("nfailed = %d  nfeval = %d\n", opt.Nfailed, opt.Nfeval)
+	if opt.Nfeval <= opt.Nsol*(1+opt.Tmax) {
+		tst.Errorf("re-sampled solutions should have been evaluated\n")
+		return
+	}
+	for _, sol := range opt.Solutions {
+		if sol.Flt[0] > 1 {
+			tst.Errorf("failed solution should have been re-sampled: x = %v\n", sol.Flt)
+			return
+		}
+	}
+
+	// abort. the initial solutions are all evaluated successfully
+	var ncalls int64
+	opt = new(Optimiser)
+	opt.Default()
+	opt.Nsol = 20
+	opt.Ncpu = 2
+	opt.Verbose = false
+	opt.FltMin = []float64{-2, -2}
+	opt.FltMax = []float64{2, 2}
+	opt.ObjFuncErr = func(sol *Solution, cpu int) error {
+		if atomic.AddInt64(&ncalls, 1) > 100 {
+			return chk.Err("simulation did not converge")
+		}
+		sol.Ova[0] = sol.Flt[0]*sol.Flt[0] + sol.Flt[1]*sol.Flt[1]
+		return nil
+	}
+	opt.Init(GenTrialSolutions, nil, nil, 0, 0, 0)
+	err := opt.SolveContext(context.Background())
+	io.Pforan("err = %v\n", err)
+	if err == nil {
+		tst.Errorf("SolveContext should have returned an error\n")
+		return
+	}
+	chk.String(tst, opt.StopReason, "failure")
+}
+
+func Test_solve06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve06. workers evaluating objective functions")
+
+	// optimiser
+	newopt := func(nworkers int) (opt *Optimiser) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 20
+		opt.Ncpu = 2
+		opt.Nworkers = nworkers
+		opt.Tmax = 100
+		opt.Seed = 1234
+		opt.Verbose = false
+		opt.FltMin = []float64{-2, -2}
+		opt.FltMax = []float64{2, 2}
+		return
+	}
+
+	// groups evaluate their own offspring
+	optA := newopt(0)
+	optA.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		f[0] = x[0]*x[0] + x[1]*x[1]
+	}, 1, 0, 0)
+	optA.Solve()
+
+	// 2 groups and 5 workers
+	ncalls := make([]int64, 5)
+	optB := newopt(5)
+	optB.Init(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+		atomic.AddInt64(&ncalls[cpu], 1)
+		f[0] = x[0]*x[0] + x[1]*x[1]
+	}, 1, 0, 0)
+	optB.Solve()
+
+	// compare
+	io.Pforan("ncalls = %v\n", ncalls)
+	var ntotal int64
+	for _, n := range ncalls {
+		ntotal += n
+	}
+	chk.Int(tst, "ncalls", int(ntotal), optB.Nfeval)
+	chk.Int(tst, "nfeval", optB.Nfeval, optA.Nfeval)
+	for i, sol := range optA.Solutions {
+		chk.Array(tst, io.Sf("flt%d", i), 1e-17, optB.Solutions[i].Flt, sol.Flt)
+		chk.Array(tst, io.Sf("ova%d", i), 1e-17, optB.Solutions[i].Ova, sol.Ova)
+	}
+}
+
+func Test_solve07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solve07. stable front criterion")
+
+	// optimiser
+	newopt := func(term Terminator) (opt *Optimiser, err error) {
+		opt = new(Optimiser)
+		opt.Default()
+		opt.Nsol = 8
+		opt.Ncpu = 1
+		opt.Verbose = false
+		opt.FltMin = []float64{0, 0}
+		opt.FltMax = []float64{1, 1}
+		opt.Terminators = []Terminator{term}
+		err = opt.InitErr(GenTrialSolutions, nil, func(f, g, h, x []float64, y []int, cpu int) {
+			f[0], f[1] = x[0], x[1]
+		}, 2, 0, 0)
+		return
+	}
+
+	// invalid number of exchange periods
+	for _, term := range []Terminator{&TermFrontStable{}, &TermAll{List: []Terminator{&TermFrontStable{}}}} {
+		_, err := newopt(term)
+		io.Pforan("err = %v\n", err)
+		if err == nil {
+			tst.Errorf("InitErr should have failed with Nexc = 0\n")
+			return
+		}
+	}
+
+	// front 0 does not change but the range of objective values does
+	term := &TermFrontStable{Nexc: 1, Tol: 1e-10}
+	opt, err := newopt(term)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	setOvas := func(ovas [][]float64) {
+		for i, sol := range opt.Solutions {
+			sol.Ova[0], sol.Ova[1] = float64(i), float64(i) // dominated
+			if i < len(ovas) {
+				copy(sol.Ova, ovas[i])
+			}
+		}
+		opt.Metrics.Compute(opt.Solutions)
+	}
+	term.Init(opt)
+	setOvas([][]float64{{0, 1}, {1, 0}})
+	if term.Done(opt) {
+		tst.Errorf("criterion must not be done without previous front\n")
+		return
+	}
+	setOvas([][]float64{{0, 1}, {1, 0}, {2, 2}, {30, 30}})
+	if !term.Done(opt) {
+		tst.Errorf("criterion must be done because front 0 has not moved\n")
+		return
+	}
+	setOvas([][]float64{{0, 0.5}, {1, 0}, {2, 2}, {30, 30}})
+	if term.Done(opt) {
+		tst.Errorf("criterion must not be done because front 0 has moved\n")
+		return
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// Terminator defines a termination criterion. Terminators are checked by Solve at exchange times;
+// i.e. after the metrics of all solutions have been computed. Criteria with a "Validate() error"
+// method are checked by Init
+type Terminator interface {
+	Name() string           // name of criterion; recorded in Stat.StopReason
+	Init(o *Optimiser)      // initialise internal data at the beginning of Solve
+	Done(o *Optimiser) bool // tells whether the evolution must stop or not
+}
+
+// TermNfeval stops the evolution when the number of function evaluations reaches Max
+type TermNfeval struct {
+	Max int // maximum number of function evaluations
+}
+
+// Name returns the name of this criterion
+func (o *TermNfeval) Name() string { return "nfeval" }
+
+// Init initialises internal data
+func (o *TermNfeval) Init(opt *Optimiser) {}
+
+// Done tells whether Nfeval ≥ Max or not
+func (o *TermNfeval) Done(opt *Optimiser) bool {
+	return opt.Nfeval >= o.Max
+}
+
+// TermWallClock stops the evolution when the elapsed (real) time exceeds Budget
+type TermWallClock struct {
+	Budget time.Duration // time budget
+	t0     time.Time     // initial time
+}
+
+// Name returns the name of this criterion
+func (o *TermWallClock) Name() string { return "wallclock" }
+
+// Init initialises internal data
+func (o *TermWallClock) Init(opt *Optimiser) { o.t0 = time.Now() }
+
+// Done tells whether the time budget has been exceeded or not
+func (o *TermWallClock) Done(opt *Optimiser) bool {
+	return time.Now().Sub(o.t0) >= o.Budget
+}
+
+// TermTarget stops the evolution when a feasible solution reaches the target objective values;
+// i.e. when Ova[i] ≤ Fref[i] + Tol for all i
+type TermTarget struct {
+	Fref []float64 // target objective values. nil => use RptFref
+	Tol  float64   // tolerance
+}
+
+// Name returns the name of this criterion
+func (o *TermTarget) Name() string { return "target" }
+
+// Init initialises internal data
+func (o *TermTarget) Init(opt *Optimiser) {}
+
+// Done tells whether the target has been reached or not
+func (o *TermTarget) Done(opt *Optimiser) bool {
+	fref := o.Fref
+	if fref == nil {
+		fref = opt.RptFref
+	}
+	if len(fref) == 0 {
+		return false
+	}
+	for _, sol := range opt.Solutions {
+		if !sol.Feasible() {
+			continue
+		}
+		reached := true
+		for i, f := range fref {
+			if sol.Ova[i] > f+o.Tol {
+				reached = false
+				break
+			}
+		}
+		if reached {
+			return true
+		}
+	}
+	return false
+}
+
+// TermStagnation stops the evolution when the best feasible Ova[0] has not improved by more than
+// Tol during the last Nexc exchange periods
+type TermStagnation struct {
+	Nexc int     // number of exchange periods
+	Tol  float64 // minimum improvement
+}
+
+// Name returns the name of this criterion
+func (o *TermStagnation) Name() string { return "stagnation" }
+
+// Init initialises internal data
+func (o *TermStagnation) Init(opt *Optimiser) {}
+
+// Done tells whether the best feasible Ova[0] has stagnated or not
+func (o *TermStagnation) Done(opt *Optimiser) bool {
+	return opt.stagnated(o.Nexc, o.Tol)
+}
+
+// TermFrontStable stops the evolution when the (normalised) feasible front 0 does not move by more
+// than Tol during Nexc consecutive exchange periods. The movement is measured by the average
+// distance from each point of the current front to the closest point of the previous front. Both
+// fronts are normalised with the current range of objective values
+type TermFrontStable struct {
+	Nexc  int         // number of exchange periods ≥ 1
+	Tol   float64     // maximum movement
+	prev  [][]float64 // previous front (not normalised)
+	count int         // number of consecutive stable periods
+}
+
+// Name returns the name of this criterion
+func (o *TermFrontStable) Name() string { return "front" }
+
+// Init initialises internal data
+func (o *TermFrontStable) Init(opt *Optimiser) {
+	o.prev = nil
+	o.count = 0
+}
+
+// Validate checks the data of this criterion
+func (o *TermFrontStable) Validate() error {
+	if o.Nexc < 1 {
+		return chk.Err("number of exchange periods of front criterion must be at least 1. Nexc = %d is invalid", o.Nexc)
+	}
+	return nil
+}
+
+// Done tells whether front 0 is stable or not
+func (o *TermFrontStable) Done(opt *Optimiser) bool {
+
+	// current front
+	m := opt.Metrics
+	var front [][]float64
+	for _, sol := range opt.Solutions {
+		if sol.Feasible() && sol.FrontId == 0 {
+			front = append(front, append([]float64{}, sol.Ova...))
+		}
+	}
+	scaled := func(f []float64, j int) float64 {
+		return (f[j] - m.Omin[j]) / (m.Omax[j] - m.Omin[j] + 1e-15)
+	}
+
+	// movement
+	if len(front) == 0 || len(o.prev) == 0 {
+		o.count = 0
+	} else {
+		dist := 0.0
+		for _, f := range front {
+			dmin := INF
+			for _, p := range o.prev {
+				d := 0.0
+				for j := 0; j < len(f); j++ {
+					δ := scaled(f, j) - scaled(p, j)
+					d += δ * δ
+				}
+				dmin = math.Min(dmin, d)
+			}
+			dist += math.Sqrt(dmin)
+		}
+		dist /= float64(len(front))
+		if dist <= o.Tol {
+			o.count++
+		} else {
+			o.count = 0
+		}
+	}
+	o.prev = front
+	return o.count >= o.Nexc
+}
+
+// TermAll combines criteria; the evolution stops when all of them are done
+type TermAll struct {
+	List []Terminator // criteria
+}
+
+// Name returns the name of this criterion
+func (o *TermAll) Name() string {
+	names := make([]string, len(o.List))
+	for i, t := range o.List {
+		names[i] = t.Name()
+	}
+	return strings.Join(names, "+")
+}
+
+// Init initialises internal data
+func (o *TermAll) Init(opt *Optimiser) {
+	for _, t := range o.List {
+		t.Init(opt)
+	}
+}
+
+// Done tells whether all criteria are done or not
+func (o *TermAll) Done(opt *Optimiser) (done bool) {
+	done = len(o.List) > 0
+	for _, t := range o.List { // all must be called because some criteria keep history
+		if !t.Done(opt) {
+			done = false
+		}
+	}
+	return
+}
+
+// validateTerminator calls the Validate method of t, if any, and of the criteria combined by TermAll
+func validateTerminator(t Terminator) error {
+	if a, ok := t.(*TermAll); ok {
+		for _, s := range a.List {
+			if err := validateTerminator(s); err != nil {
+				return err
+			}
+		}
+	}
+	if v, ok := t.(interface{ Validate() error }); ok {
+		return v.Validate()
+	}
+	return nil
+}
+
+// cloneTerminator returns a copy of t without history; e.g. for an independent run in RunMany
+//  Note: other implementations of Terminator are shared and must be safe for concurrent use
+func cloneTerminator(t Terminator) Terminator {
+	switch o := t.(type) {
+	case *TermNfeval:
+		return &TermNfeval{Max: o.Max}
+	case *TermWallClock:
+		return &TermWallClock{Budget: o.Budget}
+	case *TermTarget:
+		return &TermTarget{Fref: o.Fref, Tol: o.Tol}
+	case *TermStagnation:
+		return &TermStagnation{Nexc: o.Nexc, Tol: o.Tol}
+	case *TermFrontStable:
+		return &TermFrontStable{Nexc: o.Nexc, Tol: o.Tol}
+	case *TermAll:
+		list := make([]Terminator, len(o.List))
+		for i, s := range o.List {
+			list[i] = cloneTerminator(s)
+		}
+		return &TermAll{List: list}
+	}
+	return t
+}
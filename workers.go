@@ -0,0 +1,45 @@
+// Copyright 2015 The Goga Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goga
+
+import "sync"
+
+// startWorkers starts Nworkers goroutines that evaluate the objective functions submitted by the
+// groups. Nothing is done if Nworkers == 0 or if the workers are already running
+//  Output:
+//   stop -- stops the workers started by this call
+func (o *Optimiser) startWorkers() (stop func()) {
+	if o.Nworkers < 1 || o.workers != nil {
+		return func() {}
+	}
+	o.workers = make(chan func(icpu int))
+	for w := 0; w < o.Nworkers; w++ {
+		go func(icpu int) {
+			for job := range o.workers {
+				job(icpu)
+			}
+		}(w)
+	}
+	return func() {
+		close(o.workers)
+		o.workers = nil
+	}
+}
+
+// submit runs job on a worker if the workers are running; otherwise job runs in this goroutine.
+// job receives the index to be passed to the objective functions: the index of the worker or cpu
+//  Note: the caller must wait for job to finish; e.g. with wg
+func (o *Optimiser) submit(cpu int, wg *sync.WaitGroup, job func(icpu int)) {
+	wg.Add(1)
+	run := func(icpu int) {
+		job(icpu)
+		wg.Done()
+	}
+	if o.workers == nil {
+		run(cpu)
+		return
+	}
+	o.workers <- run
+}